@@ -0,0 +1,216 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	mrand "math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+type routeKey struct{}
+
+// withRoute returns a handle which reports the route pattern to middlewares
+// (see routeRecorder) before calling handle, and removes temp files of
+// uploads (see MultipartForm) and saves the session (see Context.Session) if
+// handle doesn't write the header after it. In the development mode, it reports
+// the request if handle doesn't answer it.
+func withRoute(pattern string, handle func(ctx *Context)) func(ctx *Context) {
+	return func(ctx *Context) {
+		if route, ok := ctx.Request.Context().Value(routeKey{}).(*string); ok {
+			*route = pattern
+		}
+		if requestUploads(ctx.Request) == nil { // not owned by a middleware
+			st := ctx.uploadState() // shared by copies of ctx, eg. classfile handlers
+			defer st.removeAll()
+		}
+		if ctx.engine.sess != nil {
+			ctx.sessionRef() // shared by copies of ctx, as the upload state
+		}
+		if !ctx.engine.dev {
+			handle(ctx)
+			ctx.saveSession()
+			return
+		}
+		w := wrapResponse(ctx.ResponseWriter)
+		ctx.ResponseWriter = w
+		handle(ctx)
+		ctx.saveSession()
+		ctx.checkAnswered(w, pattern)
+	}
+}
+
+// routeRecorder returns a request in which the route pattern matched will be
+// recorded, and a pointer to the pattern.
+func routeRecorder(r *http.Request) (*http.Request, *string) {
+	if route, ok := r.Context().Value(routeKey{}).(*string); ok {
+		return r, route
+	}
+	route := new(string)
+	return r.WithContext(context.WithValue(r.Context(), routeKey{}, route)), route
+}
+
+// wrapResponse returns w as a *responseWriter.
+func wrapResponse(w http.ResponseWriter) *responseWriter {
+	if rw, ok := w.(*responseWriter); ok {
+		return rw
+	}
+	return &responseWriter{ResponseWriter: w}
+}
+
+// -----------------------------------------------------------------------------
+
+// RequestIDHeader is the header which carries request IDs.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestID returns a middleware which assigns an ID to each request. The ID
+// is propagated from the X-Request-ID request header if it is valid, or a new
+// one is generated. It is sent back in the X-Request-ID response header, and
+// is available by Context.RequestID.
+func RequestID() func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+			h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+		})
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if c := id[i]; c <= ' ' || c >= 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func requestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestID returns the ID of the request. It returns "" if the RequestID
+// middleware isn't used.
+func (p *Context) RequestID() string {
+	return requestID(p.Request)
+}
+
+// -----------------------------------------------------------------------------
+
+// AccessLogOptions represents options of the access log middleware.
+type AccessLogOptions struct {
+	// Logger is the logger to write records. It is slog.Default() by default.
+	Logger *slog.Logger
+
+	// SampleRate is the fraction of requests to log, in (0, 1]. Requests
+	// answered with 5xx errors are always logged. 0 means 1.
+	SampleRate float64
+
+	// SkipPaths lists request paths not to log, eg. "/healthz". A path ending
+	// with "/" matches all paths under it.
+	SkipPaths []string
+
+	// TrustedProxies lists proxies (CIDRs or IPs) trusted to provide the
+	// client IP by the forwarding headers (see Context.ClientIP). It
+	// overrides those of the engine (see Engine.SetTrustedProxies).
+	TrustedProxies []string
+}
+
+// AccessLog returns a middleware which writes an access log record for each
+// request. A record has attributes: method, route (the route pattern), path,
+// status, bytes, latency, ip, user_agent and request_id (see RequestID). It
+// is logged at the Info level, or the Error level for 5xx errors.
+func AccessLog(opts ...AccessLogOptions) func(h http.Handler) http.Handler {
+	var o AccessLogOptions
+	if opts != nil {
+		o = opts[0]
+	}
+	if o.Logger == nil {
+		o.Logger = slog.Default()
+	}
+	trusted := parsePrefixes(o.TrustedProxies)
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if o.skip(r.URL.Path) {
+				h.ServeHTTP(w, r)
+				return
+			}
+			start := time.Now()
+			rw := wrapResponse(w)
+			r, route := routeRecorder(r)
+			h.ServeHTTP(rw, r)
+			status := rw.Status()
+			if status < 500 && o.SampleRate > 0 && o.SampleRate < 1 && mrand.Float64() >= o.SampleRate {
+				return
+			}
+			id := requestID(r)
+			if id == "" { // RequestID is used after AccessLog
+				id = rw.Header().Get(RequestIDHeader)
+			}
+			level := slog.LevelInfo
+			if status >= 500 {
+				level = slog.LevelError
+			}
+			o.Logger.LogAttrs(r.Context(), level, "request",
+				slog.String("method", r.Method),
+				slog.String("route", *route),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int64("bytes", rw.written),
+				slog.Duration("latency", time.Since(start)),
+				slog.String("ip", clientIP(r, trustedOf(r, trusted))),
+				slog.String("user_agent", r.UserAgent()),
+				slog.String("request_id", id),
+			)
+		})
+	}
+}
+
+func (p *AccessLogOptions) skip(path string) bool {
+	return slices.ContainsFunc(p.SkipPaths, func(skip string) bool {
+		if strings.HasSuffix(skip, "/") {
+			return strings.HasPrefix(path, skip)
+		}
+		return path == skip
+	})
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goplus/yap"
+)
+
+func accessLogEngine(buf *bytes.Buffer, opts yap.AccessLogOptions) *yap.Engine {
+	opts.Logger = slog.New(slog.NewJSONHandler(buf, nil))
+	e := newEngine()
+	e.Use(yap.RequestID(), yap.AccessLog(opts))
+	e.GET("/p/:id", func(ctx *yap.Context) {
+		ctx.TEXT(200, "text/plain", ctx.RequestID())
+	})
+	e.GET("/stream", func(ctx *yap.Context) {
+		ctx.STREAM(200, "text/plain", strings.NewReader("hello"), make([]byte, 2))
+	})
+	e.GET("/fail", func(ctx *yap.Context) {
+		ctx.TEXT(500, "text/plain", "oops")
+	})
+	return e
+}
+
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var ret []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatal("log record:", err)
+		}
+		ret = append(ret, rec)
+	}
+	buf.Reset()
+	return ret
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	e := accessLogEngine(&buf, yap.AccessLogOptions{})
+	req := httptest.NewRequest("GET", "/p/123", nil)
+	req.Header.Set("User-Agent", "test")
+	req.Header.Set(yap.RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Body.String() != "req-1" || w.Header().Get(yap.RequestIDHeader) != "req-1" {
+		t.Fatal("RequestID:", w.Body.String())
+	}
+	recs := logRecords(t, &buf)
+	if len(recs) != 1 {
+		t.Fatal("records:", recs)
+	}
+	rec := recs[0]
+	if rec["level"] != "INFO" || rec["method"] != "GET" || rec["route"] != "/p/:id" || rec["path"] != "/p/123" ||
+		rec["status"] != 200.0 || rec["bytes"] != 5.0 || rec["ip"] != "192.0.2.1" ||
+		rec["user_agent"] != "test" || rec["request_id"] != "req-1" {
+		t.Fatal("record:", rec)
+	}
+
+	// invalid request id is replaced
+	req = httptest.NewRequest("GET", "/p/1", nil)
+	req.Header.Set(yap.RequestIDHeader, "bad id")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if id := w.Body.String(); len(id) != 32 {
+		t.Fatal("generated RequestID:", id)
+	}
+	logRecords(t, &buf)
+
+	// STREAM still flushes through the wrapper
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/stream", nil))
+	if !w.Flushed || w.Body.String() != "hello" {
+		t.Fatal("STREAM:", w.Flushed, w.Body.String())
+	}
+	if rec := logRecords(t, &buf)[0]; rec["bytes"] != 5.0 || rec["route"] != "/stream" {
+		t.Fatal("STREAM record:", rec)
+	}
+
+	serve(e, "GET", "/fail")
+	if rec := logRecords(t, &buf)[0]; rec["level"] != "ERROR" || rec["status"] != 500.0 {
+		t.Fatal("error record:", rec)
+	}
+}
+
+func TestAccessLogSkip(t *testing.T) {
+	var buf bytes.Buffer
+	e := accessLogEngine(&buf, yap.AccessLogOptions{
+		SkipPaths:  []string{"/stream", "/p/"},
+		SampleRate: 1e-9,
+	})
+	serve(e, "GET", "/stream")
+	serve(e, "GET", "/p/1")
+	serve(e, "GET", "/nothing") // 404, sampled out
+	if recs := logRecords(t, &buf); len(recs) != 0 {
+		t.Fatal("skipped:", recs)
+	}
+	serve(e, "GET", "/fail")
+	if recs := logRecords(t, &buf); len(recs) != 1 {
+		t.Fatal("errors are always logged:", recs)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+
+// Assets represents static files fingerprinted by their content hashes, eg.
+// "js/app.js" is served as "js/app.3f9a0c1e5b.js".
+type Assets struct {
+	prefix string
+	fsys   fs.FS
+	hashed map[string]string // name => hashed name
+	origin map[string]string // hashed name => name
+	etags  map[string]string // name => ETag
+}
+
+// NewAssets computes content hashes of files in fsys (except hidden ones),
+// which are served under the URL path prefix.
+func NewAssets(prefix string, fsys fs.FS) (*Assets, error) {
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	p := &Assets{
+		prefix: prefix,
+		fsys:   fsys,
+		hashed: make(map[string]string),
+		origin: make(map[string]string),
+		etags:  make(map[string]string),
+	}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		sum, err := hashFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		ext := path.Ext(name)
+		hashed := name[:len(name)-len(ext)] + "." + sum[:10] + ext
+		p.hashed[name] = hashed
+		p.origin[hashed] = name
+		p.etags[name] = `"` + sum[:32] + `"`
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func hashFile(fsys fs.FS, name string) (string, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Path returns the URL path of the fingerprinted file name, eg.
+// "/static/app.3f9a0c1e5b.js". If name isn't a known file, it returns the URL
+// path without a hash.
+func (p *Assets) Path(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if hashed, ok := p.hashed[name]; ok {
+		return p.prefix + hashed
+	}
+	return p.prefix + name
+}
+
+// Manifest returns URL paths of all files, eg.
+//
+//	{"app.js": "/static/app.3f9a0c1e5b.js"}
+func (p *Assets) Manifest() map[string]string {
+	ret := make(map[string]string, len(p.hashed))
+	for name, hashed := range p.hashed {
+		ret[name] = p.prefix + hashed
+	}
+	return ret
+}
+
+// WriteManifest writes the manifest (see Manifest) in JSON, eg. to upload
+// files to a CDN.
+func (p *Assets) WriteManifest(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p.Manifest())
+}
+
+// serve serves fingerprinted files with far-future caching, and files
+// requested by their original names with revalidation.
+func (p *Assets) serve(ctx *Context) {
+	name := strings.TrimPrefix(ctx.URL.Path, p.prefix)
+	h := ctx.ResponseWriter.Header()
+	if origin, ok := p.origin[name]; ok {
+		name = origin
+		h.Set("Cache-Control", immutableCacheControl)
+	} else if _, ok = p.hashed[name]; ok {
+		h.Set("Cache-Control", "no-cache")
+	} else {
+		http.Error(ctx.ResponseWriter, "404 page not found", http.StatusNotFound)
+		return
+	}
+	f, err := http.FS(p.fsys).Open("/" + name)
+	if err != nil {
+		http.Error(ctx.ResponseWriter, "500 Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	d, err := f.Stat()
+	if err != nil {
+		http.Error(ctx.ResponseWriter, "500 Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	h.Set("ETag", p.etags[name])
+	http.ServeContent(ctx.ResponseWriter, ctx.Request, d.Name(), d.ModTime(), f)
+}
+
+// UseAssets fingerprints static files in a dir (default is "$YapFS/static")
+// at startup, and serves them under pattern. The "asset" template function
+// returns the URL path of a fingerprinted file, eg.
+//
+//	<script src="{{asset "app.js"}}"></script>
+//
+// renders "/static/app.3f9a0c1e5b.js" with y.UseAssets("/static/").
+// Fingerprinted files are served with far-future caching.
+func (p *Engine) UseAssets(pattern string, dir ...fs.FS) *Assets {
+	var fsys fs.FS
+	if dir != nil {
+		fsys = dir[0]
+	} else {
+		fsys = p.FS("static")
+	}
+	a, err := NewAssets(pattern, fsys)
+	if err != nil {
+		log.Panicln("UseAssets:", err)
+	}
+	p.Funcs(template.FuncMap{"asset": a.Path})
+	p.static(a.prefix, a.serve, nil)
+	return a
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/goplus/yap"
+)
+
+func TestAssets(t *testing.T) {
+	e := yap.New(fstest.MapFS{
+		"page_yap.html":        {Data: []byte(`<script src="{{asset "js/app.js"}}"></script>`)},
+		"static/js/app.js":     {Data: []byte("app()")},
+		"static/css/site.css":  {Data: []byte("body{}")},
+		"static/.git/HEAD":     {Data: []byte("ref")},
+		"static/.hidden.js":    {Data: []byte("x")},
+		"static/LICENSE":       {Data: []byte("MIT")},
+		"static/js/app.min.js": {Data: []byte("app()")},
+	})
+	assets := e.UseAssets("/static")
+	e.GET("/", func(ctx *yap.Context) {
+		ctx.YAP(200, "page", nil)
+	})
+
+	manifest := assets.Manifest()
+	if len(manifest) != 4 {
+		t.Fatal("Manifest:", manifest)
+	}
+	appJS := manifest["js/app.js"]
+	if !regexp.MustCompile(`^/static/js/app\.[0-9a-f]{10}\.js$`).MatchString(appJS) ||
+		!strings.HasPrefix(manifest["LICENSE"], "/static/LICENSE.") ||
+		!strings.HasSuffix(manifest["js/app.min.js"], appJS[len("/static/js/app"):]) {
+		t.Fatal("Manifest:", manifest)
+	}
+	if assets.Path("/js/app.js") != appJS || assets.Path("none.js") != "/static/none.js" {
+		t.Fatal("Path:", assets.Path("/js/app.js"))
+	}
+
+	if w := serveWith(e, "GET", "/"); w.Body.String() != `<script src="`+appJS+`"></script>` {
+		t.Fatal("asset:", w.Body.String())
+	}
+	w := serveWith(e, "GET", appJS)
+	etag := w.Header().Get("ETag")
+	if w.Code != 200 || w.Body.String() != "app()" || etag == "" ||
+		w.Header().Get("Cache-Control") != "public, max-age=31536000, immutable" {
+		t.Fatal("GET hashed:", w.Code, w.Header())
+	}
+	if w = serveWith(e, "GET", appJS, "If-None-Match", etag); w.Code != 304 {
+		t.Fatal("If-None-Match:", w.Code)
+	}
+	if w = serveWith(e, "GET", "/static/js/app.js"); w.Code != 200 || w.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatal("GET origin:", w.Code, w.Header())
+	}
+	if w = serveWith(e, "GET", "/static/.git/HEAD"); w.Code != 404 {
+		t.Fatal("GET hidden:", w.Code)
+	}
+
+	var b strings.Builder
+	if err := assets.WriteManifest(&b); err != nil {
+		t.Fatal(err)
+	}
+	var ret map[string]string
+	if err := json.Unmarshal([]byte(b.String()), &ret); err != nil || ret["css/site.css"] != manifest["css/site.css"] {
+		t.Fatal("WriteManifest:", b.String())
+	}
+}
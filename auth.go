@@ -0,0 +1,180 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrNoCredentials is returned by an Authenticator if a request doesn't
+	// carry credentials of its scheme.
+	ErrNoCredentials = errors.New("yap: no credentials")
+
+	// ErrInvalidCredentials is returned by an Authenticator if credentials of
+	// a request are rejected.
+	ErrInvalidCredentials = errors.New("yap: invalid credentials")
+)
+
+// Claims represents claims of an authenticated identity, eg. claims of a JWT.
+type Claims map[string]any
+
+// Subject returns the "sub" claim.
+func (p Claims) Subject() string {
+	sub, _ := p["sub"].(string)
+	return sub
+}
+
+// Authenticator represents an authentication scheme.
+type Authenticator interface {
+	// Authenticate returns claims of the identity who sends the request. It
+	// returns ErrNoCredentials if the request carries no credentials of this
+	// scheme.
+	Authenticate(r *http.Request) (Claims, error)
+}
+
+// AuthenticatorFunc is an adapter to allow the use of ordinary functions as
+// authenticators.
+type AuthenticatorFunc func(r *http.Request) (Claims, error)
+
+// Authenticate calls f(r).
+func (f AuthenticatorFunc) Authenticate(r *http.Request) (Claims, error) {
+	return f(r)
+}
+
+// AuthOptions represents options of the authentication middleware.
+type AuthOptions struct {
+	// Required specifies whether anonymous requests are rejected. Otherwise
+	// they are passed through, and Context.Claims returns nil.
+	Required bool
+
+	// ErrorHandler is called when authentication fails. If it is nil, a 401
+	// Unauthorized error is returned.
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type claimsKey struct{}
+
+// Auth returns a middleware which authenticates requests by specified schemes.
+// Schemes are tried in order until one of them finds credentials. Requests
+// with invalid credentials are rejected with 401 Unauthorized.
+//
+// Claims of the authenticated identity are available by Context.Claims.
+func Auth(opts AuthOptions, schemes ...Authenticator) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := authenticate(r, schemes)
+			if errors.Is(err, ErrNoCredentials) && !opts.Required {
+				h.ServeHTTP(w, r)
+				return
+			}
+			if err != nil {
+				if opts.ErrorHandler != nil {
+					opts.ErrorHandler(w, r, err)
+				} else {
+					unauthorized(w, err)
+				}
+				return
+			}
+			h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claimsKey{}, claims)))
+		})
+	}
+}
+
+func authenticate(r *http.Request, schemes []Authenticator) (Claims, error) {
+	for _, scheme := range schemes {
+		claims, err := scheme.Authenticate(r)
+		if !errors.Is(err, ErrNoCredentials) {
+			if err == nil && claims == nil {
+				claims = Claims{}
+			}
+			return claims, err
+		}
+	}
+	return nil, ErrNoCredentials
+}
+
+func unauthorized(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrNoCredentials) {
+		w.Header().Set("WWW-Authenticate", `Bearer`)
+	} else {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+// Claims returns claims of the authenticated identity. It returns nil if the
+// request is anonymous. See Auth.
+func (p *Context) Claims() Claims {
+	claims, _ := p.Request.Context().Value(claimsKey{}).(Claims)
+	return claims
+}
+
+// -----------------------------------------------------------------------------
+
+// BearerToken returns the token of the `Authorization: Bearer <token>` header.
+func BearerToken(r *http.Request) (token string, ok bool) {
+	auth := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(auth) > len(prefix) && strings.EqualFold(auth[:len(prefix)], prefix) {
+		return strings.TrimSpace(auth[len(prefix):]), true
+	}
+	return "", false
+}
+
+// BearerAuth returns a scheme which authenticates requests by the
+// `Authorization: Bearer <token>` header. verify returns claims of a token.
+func BearerAuth(verify func(token string) (Claims, error)) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (Claims, error) {
+		token, ok := BearerToken(r)
+		if !ok {
+			return nil, ErrNoCredentials
+		}
+		return verify(token)
+	})
+}
+
+// APIKeyAuth returns a scheme which authenticates requests by an API key sent
+// in the specified header (eg. "X-API-Key"). verify returns claims of a key.
+func APIKeyAuth(header string, verify func(key string) (Claims, error)) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (Claims, error) {
+		key := r.Header.Get(header)
+		if key == "" {
+			return nil, ErrNoCredentials
+		}
+		return verify(key)
+	})
+}
+
+// StaticTokens returns a verify function (see BearerAuth and APIKeyAuth) by a
+// token => subject map.
+func StaticTokens(tokens map[string]string) func(token string) (Claims, error) {
+	return func(token string) (Claims, error) {
+		for t, sub := range tokens {
+			if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+				return Claims{"sub": sub}, nil
+			}
+		}
+		return nil, ErrInvalidCredentials
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,301 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTKey represents a key to verify JWT signatures.
+type JWTKey struct {
+	// ID is the key id ("kid"). If it is not empty, only tokens with the same
+	// "kid" header are verified by this key.
+	ID string
+
+	// Algorithm is the signing algorithm ("alg"), eg. "RS256". If it is empty,
+	// all algorithms suitable for Key are allowed.
+	Algorithm string
+
+	// Key is the verification key. It can be []byte (HS256, HS384, HS512),
+	// *rsa.PublicKey (RS*, PS*), *ecdsa.PublicKey (ES*) or ed25519.PublicKey
+	// (EdDSA).
+	Key any
+}
+
+func (p *JWTKey) accept(alg, kid string) bool {
+	if p.ID != "" && kid != "" && p.ID != kid {
+		return false
+	}
+	if p.Algorithm != "" {
+		return p.Algorithm == alg
+	}
+	switch k := p.Key.(type) {
+	case []byte:
+		return strings.HasPrefix(alg, "HS")
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return alg == "ES256"
+		case elliptic.P384():
+			return alg == "ES384"
+		case elliptic.P521():
+			return alg == "ES512"
+		}
+	case ed25519.PublicKey:
+		return alg == "EdDSA"
+	}
+	return false
+}
+
+// JWTOptions represents options of verifying JWTs.
+type JWTOptions struct {
+	// Keys are keys to verify JWT signatures. See also ParseJWKS.
+	Keys []JWTKey
+
+	// Audience lists acceptable audiences. If it isn't empty, the "aud" claim
+	// must contain one of them.
+	Audience []string
+
+	// Issuer is the expected "iss" claim, if it isn't empty.
+	Issuer string
+
+	// Leeway is the allowed clock skew when checking "exp" and "nbf".
+	Leeway time.Duration
+
+	// RequireExpiration specifies whether the "exp" claim is required.
+	RequireExpiration bool
+
+	// Now returns the current time. It is time.Now by default.
+	Now func() time.Time
+}
+
+// JWTAuth verifies JWTs. It implements the Authenticator interface by reading
+// tokens from the `Authorization: Bearer <token>` header.
+type JWTAuth struct {
+	opts   JWTOptions
+	parser *jwt.Parser
+}
+
+// NewJWTAuth creates a JWTAuth object.
+func NewJWTAuth(opts JWTOptions) *JWTAuth {
+	var algs []string
+	for _, alg := range []string{
+		"HS256", "HS384", "HS512", "RS256", "RS384", "RS512", "PS256", "PS384", "PS512",
+		"ES256", "ES384", "ES512", "EdDSA",
+	} {
+		for i := range opts.Keys {
+			if opts.Keys[i].accept(alg, "") {
+				algs = append(algs, alg)
+				break
+			}
+		}
+	}
+	popts := []jwt.ParserOption{jwt.WithValidMethods(algs), jwt.WithLeeway(opts.Leeway)}
+	if opts.Issuer != "" {
+		popts = append(popts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.RequireExpiration {
+		popts = append(popts, jwt.WithExpirationRequired())
+	}
+	if opts.Now != nil {
+		popts = append(popts, jwt.WithTimeFunc(opts.Now))
+	}
+	return &JWTAuth{opts: opts, parser: jwt.NewParser(popts...)}
+}
+
+// Verify verifies a JWT and returns its claims. It checks the signature, and
+// the "exp", "nbf", "aud" and "iss" claims.
+func (p *JWTAuth) Verify(token string) (Claims, error) {
+	claims := make(jwt.MapClaims)
+	_, err := p.parser.ParseWithClaims(token, claims, p.keyfunc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	if aud := p.opts.Audience; len(aud) > 0 {
+		got, err := claims.GetAudience()
+		if err != nil || !slices.ContainsFunc(got, func(a string) bool {
+			return slices.Contains(aud, a)
+		}) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, jwt.ErrTokenInvalidAudience)
+		}
+	}
+	return Claims(claims), nil
+}
+
+func (p *JWTAuth) keyfunc(t *jwt.Token) (any, error) {
+	alg, _ := t.Header["alg"].(string)
+	kid, _ := t.Header["kid"].(string)
+	var keys []jwt.VerificationKey
+	for i := range p.opts.Keys {
+		if key := &p.opts.Keys[i]; key.accept(alg, kid) {
+			keys = append(keys, key.Key)
+		}
+	}
+	if keys == nil {
+		return nil, errors.New("no key to verify the token")
+	}
+	return jwt.VerificationKeySet{Keys: keys}, nil
+}
+
+// Authenticate implements the Authenticator interface.
+func (p *JWTAuth) Authenticate(r *http.Request) (Claims, error) {
+	token, ok := BearerToken(r)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	return p.Verify(token)
+}
+
+// -----------------------------------------------------------------------------
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS parses a JSON Web Key Set (RFC 7517). Keys of types "oct", "RSA",
+// "EC" and "OKP" (Ed25519) are supported, and keys not for signatures are
+// ignored.
+func ParseJWKS(data []byte) ([]JWTKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	ret := make([]JWTKey, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.decode()
+		if err != nil {
+			return nil, fmt.Errorf("jwks: key %q: %w", k.Kid, err)
+		}
+		ret = append(ret, JWTKey{ID: k.Kid, Algorithm: k.Alg, Key: key})
+	}
+	return ret, nil
+}
+
+// ReadJWKS reads a JSON Web Key Set file from fsys. See ParseJWKS.
+func ReadJWKS(fsys fs.FS, name string) ([]JWTKey, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+func (k *jwk) decode() (any, error) {
+	switch k.Kty {
+	case "oct":
+		return b64(k.K)
+	case "RSA":
+		n, err := b64(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := b64(k.E)
+		if err != nil {
+			return nil, err
+		}
+		eInt := new(big.Int).SetBytes(e)
+		if !eInt.IsInt64() || eInt.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(eInt.Int64())}, nil
+	case "EC":
+		return k.decodeEC()
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, errors.New("unsupported curve: " + k.Crv)
+		}
+		x, err := b64(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, errors.New("unsupported key type: " + k.Kty)
+}
+
+func (k *jwk) decodeEC() (any, error) {
+	var curve elliptic.Curve
+	var ec ecdh.Curve
+	switch k.Crv {
+	case "P-256":
+		curve, ec = elliptic.P256(), ecdh.P256()
+	case "P-384":
+		curve, ec = elliptic.P384(), ecdh.P384()
+	case "P-521":
+		curve, ec = elliptic.P521(), ecdh.P521()
+	default:
+		return nil, errors.New("unsupported curve: " + k.Crv)
+	}
+	x, err := b64(k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := b64(k.Y)
+	if err != nil {
+		return nil, err
+	}
+	size := (curve.Params().BitSize + 7) / 8
+	if len(x) != size || len(y) != size {
+		return nil, errors.New("invalid EC key")
+	}
+	point := append(append([]byte{4}, x...), y...)
+	if _, err = ec.NewPublicKey(point); err != nil { // check the point is on the curve
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+}
+
+func b64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,206 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/goplus/yap"
+	"github.com/goplus/yap/ytest/auth"
+	"github.com/goplus/yap/ytest/auth/jwt"
+	"github.com/qiniu/x/mockhttp"
+)
+
+func authEngine(opts yap.AuthOptions, schemes ...yap.Authenticator) *yap.Engine {
+	e := newEngine()
+	e.Use(yap.Auth(opts, schemes...))
+	e.GET("/me", func(ctx *yap.Context) {
+		if claims := ctx.Claims(); claims != nil {
+			ctx.TEXT(200, "text/plain", claims.Subject())
+			return
+		}
+		ctx.TEXT(200, "text/plain", "anonymous")
+	})
+	return e
+}
+
+// authGet sends GET /me to e with an authorization composer.
+func authGet(t *testing.T, e http.Handler, a auth.RTComposer) (int, string) {
+	t.Helper()
+	tr := mockhttp.NewTransport()
+	tr.ListenAndServe("example.com", e)
+	rt := http.RoundTripper(tr)
+	if a != nil {
+		rt = a.Compose(rt)
+	}
+	c := http.Client{Transport: rt}
+	resp, err := c.Get("http://example.com/me")
+	if err != nil {
+		t.Fatal("GET /me failed:", err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(b)
+}
+
+func TestJWTAuthHMAC(t *testing.T) {
+	now := time.Now()
+	e := authEngine(yap.AuthOptions{}, yap.NewJWTAuth(yap.JWTOptions{
+		Keys:     []yap.JWTKey{{Key: []byte("secret")}},
+		Audience: []string{"api", "web"},
+		Issuer:   "yap",
+		Leeway:   time.Minute,
+	}))
+	token := func() *jwt.Signaturer {
+		return jwt.HS256("secret").Set("sub", "alice").Set("iss", "yap").Audience("web")
+	}
+	if code, body := authGet(t, e, token().Expiration(now.Add(time.Hour))); code != 200 || body != "alice" {
+		t.Fatal("HS256:", code, body)
+	}
+	if code, _ := authGet(t, e, token().Expiration(now.Add(-30*time.Second))); code != 200 {
+		t.Fatal("HS256 expired within leeway:", code)
+	}
+	if code, _ := authGet(t, e, token().Expiration(now.Add(-time.Hour))); code != 401 {
+		t.Fatal("HS256 expired:", code)
+	}
+	if code, _ := authGet(t, e, token().NotBefore(now.Add(time.Hour))); code != 401 {
+		t.Fatal("HS256 not before:", code)
+	}
+	if code, _ := authGet(t, e, token().Audience("other")); code != 401 {
+		t.Fatal("HS256 audience:", code)
+	}
+	if code, _ := authGet(t, e, token().Set("iss", "other")); code != 401 {
+		t.Fatal("HS256 issuer:", code)
+	}
+	if code, _ := authGet(t, e, jwt.HS512("secret").Set("iss", "yap").Audience("api")); code != 200 {
+		t.Fatal("HS512:", code)
+	}
+	if code, _ := authGet(t, e, jwt.HS256("bad").Set("iss", "yap").Audience("api")); code != 401 {
+		t.Fatal("HS256 bad secret:", code)
+	}
+	if code, body := authGet(t, e, nil); code != 200 || body != "anonymous" {
+		t.Fatal("anonymous:", code, body)
+	}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func TestJWTAuthJWKS(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	jwks := fmt.Sprintf(`{"keys":[
+		{"kty":"RSA","kid":"rsa1","alg":"RS256","n":"%s","e":"%s"},
+		{"kty":"EC","kid":"ec1","crv":"P-256","x":"%s","y":"%s"},
+		{"kty":"OKP","kid":"ed1","crv":"Ed25519","x":"%s"},
+		{"kty":"RSA","kid":"enc","use":"enc","n":"AQAB","e":"AQAB"}
+	]}`,
+		b64(rsaKey.N.Bytes()), b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+		b64(ecKey.X.FillBytes(make([]byte, 32))), b64(ecKey.Y.FillBytes(make([]byte, 32))),
+		b64(edPub))
+	keys, err := yap.ReadJWKS(fstest.MapFS{"jwks.json": {Data: []byte(jwks)}}, "jwks.json")
+	if err != nil || len(keys) != 3 {
+		t.Fatal("ReadJWKS:", len(keys), err)
+	}
+	e := authEngine(yap.AuthOptions{Required: true}, yap.NewJWTAuth(yap.JWTOptions{
+		Keys:              keys,
+		RequireExpiration: true,
+	}))
+	exp := time.Now().Add(time.Hour)
+	for _, c := range []struct {
+		name string
+		sign *jwt.Signaturer
+		code int
+	}{
+		{"RS256", jwt.RS256(rsaKey).KeyID("rsa1"), 200},
+		{"RS256 without kid", jwt.RS256(rsaKey), 200},
+		{"RS256 wrong kid", jwt.RS256(rsaKey).KeyID("ec1"), 401},
+		{"ES256", jwt.ES256(ecKey).KeyID("ec1"), 200},
+		{"EdDSA", jwt.EdDSA(edKey).KeyID("ed1"), 200},
+		{"HS256 not allowed", jwt.HS256("secret"), 401},
+	} {
+		if code, _ := authGet(t, e, c.sign.Set("sub", "bob").Expiration(exp)); code != c.code {
+			t.Fatal(c.name, code)
+		}
+	}
+	if code, _ := authGet(t, e, jwt.RS256(rsaKey).Set("sub", "bob")); code != 401 {
+		t.Fatal("exp required:", code)
+	}
+	if code, _ := authGet(t, e, nil); code != 401 {
+		t.Fatal("anonymous:", code)
+	}
+	if _, err := yap.ParseJWKS([]byte(`{"keys":[{"kty":"EC","crv":"P-256","x":"AQ","y":"AQ"}]}`)); err == nil {
+		t.Fatal("ParseJWKS: invalid EC key accepted")
+	}
+}
+
+func TestBearerAndAPIKeyAuth(t *testing.T) {
+	tokens := yap.StaticTokens(map[string]string{"t0ken": "svc"})
+	e := authEngine(yap.AuthOptions{}, yap.BearerAuth(tokens), yap.APIKeyAuth("X-API-Key", tokens))
+	if code, body := authGet(t, e, jwt.HS256("x")); code != 401 || body == "" {
+		t.Fatal("bearer invalid:", code)
+	}
+	w := serve(e, "GET", "/me")
+	if w.Body.String() != "anonymous" {
+		t.Fatal("anonymous:", w.Body.String())
+	}
+	for _, h := range [][2]string{{"Authorization", "Bearer t0ken"}, {"X-API-Key", "t0ken"}} {
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set(h[0], h[1])
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		if w.Code != 200 || w.Body.String() != "svc" {
+			t.Fatal(h[0], w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestAuthWrappedNoCredentials(t *testing.T) {
+	cookieAuth := yap.AuthenticatorFunc(func(r *http.Request) (yap.Claims, error) {
+		return nil, fmt.Errorf("cookie auth: %w", yap.ErrNoCredentials)
+	})
+	e := authEngine(yap.AuthOptions{}, cookieAuth, yap.APIKeyAuth("X-API-Key", yap.StaticTokens(map[string]string{"t0ken": "svc"})))
+	if w := serve(e, "GET", "/me"); w.Code != 200 || w.Body.String() != "anonymous" {
+		t.Fatal("anonymous:", w.Code, w.Body.String())
+	}
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("X-API-Key", "t0ken")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Code != 200 || w.Body.String() != "svc" {
+		t.Fatal("next scheme:", w.Code, w.Body.String())
+	}
+	e = authEngine(yap.AuthOptions{Required: true}, cookieAuth)
+	if w = serve(e, "GET", "/me"); w.Code != 401 || w.Header().Get("WWW-Authenticate") != "Bearer" {
+		t.Fatal("required:", w.Code, w.Header())
+	}
+}
@@ -0,0 +1,296 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+
+// Roles returns the "roles" claim (or the "role" claim if "roles" doesn't
+// exist). A string claim is treated as a space-separated list.
+func (p Claims) Roles() []string {
+	if v, ok := p["roles"]; ok {
+		return claimList(v)
+	}
+	return claimList(p["role"])
+}
+
+// Scopes returns the "scope" claim (or the "scp" claim if "scope" doesn't
+// exist). A string claim is treated as a space-separated list (RFC 8693).
+func (p Claims) Scopes() []string {
+	if v, ok := p["scope"]; ok {
+		return claimList(v)
+	}
+	return claimList(p["scp"])
+}
+
+func claimList(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []string:
+		return v
+	case []any:
+		ret := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				ret = append(ret, s)
+			}
+		}
+		return ret
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+// Policy represents an authorization rule of routes.
+//
+// A request denied by a policy is answered with 401 Unauthorized if it is
+// anonymous (see Context.Claims), otherwise with 403 Forbidden.
+type Policy struct {
+	// Name describes the policy. It is shown in route introspection.
+	Name string
+
+	// Allow reports whether the request is allowed.
+	Allow func(ctx *Context) bool
+}
+
+// NewPolicy creates a policy by a custom predicate.
+func NewPolicy(name string, allow func(ctx *Context) bool) *Policy {
+	return &Policy{Name: name, Allow: allow}
+}
+
+// String returns the name of the policy.
+func (p *Policy) String() string {
+	return p.Name
+}
+
+// RequireAuth returns a policy which allows authenticated requests only.
+func RequireAuth() *Policy {
+	return NewPolicy("authenticated", func(ctx *Context) bool {
+		return ctx.Claims() != nil
+	})
+}
+
+// RequireRoles returns a policy which allows requests whose identity has any
+// of the specified roles. See Claims.Roles.
+func RequireRoles(roles ...string) *Policy {
+	return NewPolicy("roles("+strings.Join(roles, ",")+")", func(ctx *Context) bool {
+		return slices.ContainsFunc(ctx.Claims().Roles(), func(role string) bool {
+			return slices.Contains(roles, role)
+		})
+	})
+}
+
+// RequireScopes returns a policy which allows requests whose identity has all
+// of the specified scopes. See Claims.Scopes.
+func RequireScopes(scopes ...string) *Policy {
+	return NewPolicy("scopes("+strings.Join(scopes, ",")+")", func(ctx *Context) bool {
+		if len(scopes) == 0 {
+			return ctx.Claims() != nil
+		}
+		granted := ctx.Claims().Scopes()
+		for _, scope := range scopes {
+			if !slices.Contains(granted, scope) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// authorize checks policies, and answers the request with 401 or 403 if it is
+// denied.
+func (p *Context) authorize(policies []*Policy) bool {
+	for _, policy := range policies {
+		if !policy.Allow(p) {
+			if p.Claims() == nil {
+				unauthorized(p.ResponseWriter, ErrNoCredentials)
+			} else {
+				http.Error(p.ResponseWriter, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			}
+			return false
+		}
+	}
+	return true
+}
+
+func authorized(policies []*Policy, handle func(ctx *Context)) func(ctx *Context) {
+	if len(policies) == 0 {
+		return handle
+	}
+	return func(ctx *Context) {
+		if ctx.authorize(policies) {
+			handle(ctx)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// Group represents a group of routes sharing a path prefix, middlewares and
+// policies.
+type Group struct {
+	engine   *Engine
+	prefix   string
+	policies []*Policy
+	mws      []func(h http.Handler) http.Handler
+}
+
+// Group creates a route group with a path prefix and policies. For example:
+//
+//	admin := y.Group("/admin", yap.RequireRoles("admin"))
+//	admin.GET("/users", listUsers)
+func (p *Engine) Group(prefix string, policies ...*Policy) *Group {
+	return &Group{engine: p, prefix: prefix, policies: policies}
+}
+
+// Require returns a route group (without a path prefix) with policies. It is
+// used to declare policies of a single route. For example:
+//
+//	y.Require(yap.RequireScopes("articles:write")).POST("/articles", create)
+func (p *Engine) Require(policies ...*Policy) *Group {
+	return p.Group("", policies...)
+}
+
+// With returns a route group (without a path prefix) with middlewares. It is
+// used to apply middlewares to a single route. For example:
+//
+//	y.With(loginLimiter).POST("/login", login)
+func (p *Engine) With(mws ...func(h http.Handler) http.Handler) *Group {
+	return p.Group("").With(mws...)
+}
+
+// Group creates a sub group. Its middlewares and policies apply after those
+// of the parent group.
+func (p *Group) Group(prefix string, policies ...*Policy) *Group {
+	return &Group{
+		engine:   p.engine,
+		prefix:   p.prefix + prefix,
+		policies: append(slices.Clip(p.policies), policies...),
+		mws:      slices.Clip(p.mws),
+	}
+}
+
+// With returns a sub group (without a path prefix) with more middlewares.
+func (p *Group) With(mws ...func(h http.Handler) http.Handler) *Group {
+	ret := p.Group("")
+	ret.Use(mws...)
+	return ret
+}
+
+// Use adds middlewares which apply to routes registered to the group later.
+// Middlewares run before policies of the group are checked. The first
+// middleware added is the outermost one.
+func (p *Group) Use(mws ...func(h http.Handler) http.Handler) {
+	p.mws = append(p.mws, mws...)
+}
+
+// Require returns a sub group (without a path prefix) with more policies.
+func (p *Group) Require(policies ...*Policy) *Group {
+	return p.Group("", policies...)
+}
+
+// GET is a shortcut for Group.Route(http.MethodGet, path, handle)
+func (p *Group) GET(path string, handle func(ctx *Context)) {
+	p.Route(http.MethodGet, path, handle)
+}
+
+// HEAD is a shortcut for Group.Route(http.MethodHead, path, handle)
+func (p *Group) HEAD(path string, handle func(ctx *Context)) {
+	p.Route(http.MethodHead, path, handle)
+}
+
+// OPTIONS is a shortcut for Group.Route(http.MethodOptions, path, handle)
+func (p *Group) OPTIONS(path string, handle func(ctx *Context)) {
+	p.Route(http.MethodOptions, path, handle)
+}
+
+// POST is a shortcut for Group.Route(http.MethodPost, path, handle)
+func (p *Group) POST(path string, handle func(ctx *Context)) {
+	p.Route(http.MethodPost, path, handle)
+}
+
+// PUT is a shortcut for Group.Route(http.MethodPut, path, handle)
+func (p *Group) PUT(path string, handle func(ctx *Context)) {
+	p.Route(http.MethodPut, path, handle)
+}
+
+// PATCH is a shortcut for Group.Route(http.MethodPatch, path, handle)
+func (p *Group) PATCH(path string, handle func(ctx *Context)) {
+	p.Route(http.MethodPatch, path, handle)
+}
+
+// DELETE is a shortcut for Group.Route(http.MethodDelete, path, handle)
+func (p *Group) DELETE(path string, handle func(ctx *Context)) {
+	p.Route(http.MethodDelete, path, handle)
+}
+
+// Route registers a new request handle with the given path (relative to the
+// group prefix) and method. Policies of the group are checked before handle
+// is called.
+func (p *Group) Route(method, path string, handle func(ctx *Context)) {
+	p.engine.route(method, p.prefix+path, p.wrap(handle), p.policies)
+}
+
+// Handle registers the handler function for the given pattern (relative to
+// the group prefix) to Engine.Mux.
+func (p *Group) Handle(pattern string, handle func(ctx *Context)) {
+	p.engine.handle(p.prefix+pattern, p.wrap(handle), p.policies)
+}
+
+type groupCtxKey struct{}
+
+// wrap applies policies and middlewares of the group to handle. The chain of
+// middlewares is built once, and the Context is passed through it by the
+// request context.
+func (p *Group) wrap(handle func(ctx *Context)) func(ctx *Context) {
+	handle = authorized(p.policies, handle)
+	if len(p.mws) == 0 {
+		return handle
+	}
+	h := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context().Value(groupCtxKey{}).(*Context)
+		ctx.ResponseWriter, ctx.Request = w, r
+		handle(ctx)
+	}))
+	for i := len(p.mws) - 1; i >= 0; i-- {
+		h = p.mws[i](h)
+	}
+	return func(ctx *Context) {
+		r := ctx.Request
+		h.ServeHTTP(ctx.ResponseWriter, r.WithContext(context.WithValue(r.Context(), groupCtxKey{}, ctx)))
+	}
+}
+
+// ProtoRoute registers a YAP handler with a prototype.
+func (p *Group) ProtoRoute(method, path string, proto HandlerProto) {
+	p.Route(method, path, protoHandle(proto))
+}
+
+// ProtoHandle registers a YAP handler with a prototype.
+func (p *Group) ProtoHandle(pattern string, proto HandlerProto) {
+	p.Handle(pattern, protoHandle(proto))
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,178 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goplus/yap"
+)
+
+// users authenticates requests by the `X-User` header.
+var users = yap.AuthenticatorFunc(func(r *http.Request) (yap.Claims, error) {
+	switch r.Header.Get("X-User") {
+	case "":
+		return nil, yap.ErrNoCredentials
+	case "admin":
+		return yap.Claims{"sub": "admin", "roles": []any{"admin"}, "scope": "articles:read articles:write"}, nil
+	case "reader":
+		return yap.Claims{"sub": "reader", "role": "user", "scp": []any{"articles:read"}}, nil
+	}
+	return nil, yap.ErrInvalidCredentials
+})
+
+func serveAs(e http.Handler, method, path, user string) int {
+	req := httptest.NewRequest(method, path, nil)
+	if user != "" {
+		req.Header.Set("X-User", user)
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestPolicies(t *testing.T) {
+	e := newEngine()
+	e.Use(yap.Auth(yap.AuthOptions{}, users))
+	ok := func(ctx *yap.Context) { ctx.TEXT(200, "text/plain", "ok") }
+	e.GET("/public", ok)
+	e.Require(yap.RequireScopes("articles:write")).POST("/articles", ok)
+	e.Require(yap.RequireAuth()).GET("/articles", ok)
+	admin := e.Group("/admin", yap.RequireRoles("admin"))
+	admin.GET("/users", ok)
+	admin.Group("/audit", yap.NewPolicy("weekday", func(ctx *yap.Context) bool {
+		return ctx.Param("day") != "sunday"
+	})).GET("/log", ok)
+
+	for _, c := range []struct {
+		method, path, user string
+		code               int
+	}{
+		{"GET", "/public", "", 200},
+		{"GET", "/articles", "", 401},
+		{"GET", "/articles", "reader", 200},
+		{"POST", "/articles", "", 401},
+		{"POST", "/articles", "reader", 403},
+		{"POST", "/articles", "admin", 200},
+		{"GET", "/admin/users", "", 401},
+		{"GET", "/admin/users", "reader", 403},
+		{"GET", "/admin/users", "admin", 200},
+		{"GET", "/admin/audit/log", "reader", 403},
+		{"GET", "/admin/audit/log", "admin", 200},
+		{"GET", "/admin/audit/log?day=sunday", "admin", 403},
+	} {
+		if code := serveAs(e, c.method, c.path, c.user); code != c.code {
+			t.Fatal(c.method, c.path, c.user, code)
+		}
+	}
+
+	var routes []string
+	for _, r := range e.Routes() {
+		routes = append(routes, r.String())
+	}
+	if got := strings.Join(routes, "\n"); got != `GET /public
+POST /articles [scopes(articles:write)]
+GET /articles [authenticated]
+GET /admin/users [roles(admin)]
+GET /admin/audit/log [roles(admin) weekday]` {
+		t.Fatal("Routes:", got)
+	}
+}
+
+type PolicyAppV2 struct {
+	yap.AppV2
+}
+
+func (p *PolicyAppV2) Main() {
+	p.Use(yap.Auth(yap.AuthOptions{}, users))
+	yap.XGot_AppV2_Main(p, &policyHandler{fname: "get_admin"}, &policyHandler{fname: "handle"})
+}
+
+type policyHandler struct {
+	yap.Handler
+	*PolicyAppV2
+	fname string
+}
+
+func (p *policyHandler) Main(ctx *yap.Context) {
+	p.Handler.Main(ctx)
+	ctx.Text__2("ok")
+}
+
+func (p *policyHandler) Policies() []*yap.Policy {
+	return []*yap.Policy{yap.RequireRoles("admin")}
+}
+
+func (p *policyHandler) Classfname() string {
+	return p.fname
+}
+
+func (p *policyHandler) Classclone() yap.HandlerProto {
+	ret := *p
+	return &ret
+}
+
+func TestClassfilePolicies(t *testing.T) {
+	app := new(PolicyAppV2)
+	app.InitYap()
+	app.SetLAS(func(addr string, h http.Handler) error { return nil })
+	app.Main()
+	for _, c := range []struct {
+		path, user string
+		code       int
+	}{
+		{"/admin", "", 401},
+		{"/admin", "reader", 403},
+		{"/admin", "admin", 200},
+		{"/other", "reader", 403},
+	} {
+		if code := serveAs(app, "GET", c.path, c.user); code != c.code {
+			t.Fatal(c.path, c.user, code)
+		}
+	}
+	routes := app.Routes()
+	if len(routes) != 2 || routes[0].String() != "GET /admin [roles(admin)]" || routes[1].String() != "* / [roles(admin)]" {
+		t.Fatal("Routes:", routes)
+	}
+}
+
+func TestGroupMiddlewareChain(t *testing.T) {
+	e := yap.New()
+	built := 0
+	g := e.Group("/api").With(func(h http.Handler) http.Handler {
+		built++
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Group", "api")
+			h.ServeHTTP(w, r)
+		})
+	})
+	g.GET("/items/:id", func(ctx *yap.Context) {
+		ctx.Text__2("item " + ctx.Param("id"))
+	})
+	for _, id := range []string{"1", "2", "3"} {
+		w := serve(e, "GET", "/api/items/"+id)
+		if w.Code != 200 || w.Body.String() != "item "+id || w.Header().Get("X-Group") != "api" {
+			t.Fatal("GET /api/items:", w.Code, w.Body.String(), w.Header())
+		}
+	}
+	if built != 1 {
+		t.Fatal("middleware chain built per request:", built)
+	}
+}
@@ -0,0 +1,616 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// CachedResponse represents a response kept by a CacheStore.
+type CachedResponse struct {
+	Status     int
+	Header     http.Header
+	Body       []byte
+	Tags       []string  // see Context.AddCacheTags
+	Created    time.Time // when the response is generated
+	Expires    time.Time // the response is fresh until Expires
+	StaleUntil time.Time // the response can be served stale until StaleUntil
+}
+
+// CacheStore represents a storage of cached responses.
+type CacheStore interface {
+	// Get returns the response of key.
+	Get(key string) (resp *CachedResponse, ok bool)
+
+	// Set saves the response of key. The response is useless after
+	// resp.StaleUntil.
+	Set(key string, resp *CachedResponse)
+
+	// Delete removes the response of key.
+	Delete(key string)
+
+	// Purge removes responses tagged with any of tags.
+	Purge(tags ...string)
+}
+
+// MemoryCache is an in-memory CacheStore which evicts the least recently used
+// responses.
+type MemoryCache struct {
+	max   int
+	lru   *list.List // elements of *memCacheEntry, the most recently used first
+	items map[string]*list.Element
+	tags  map[string]map[string]struct{} // tag => keys
+	mutex sync.Mutex
+}
+
+type memCacheEntry struct {
+	key  string
+	resp *CachedResponse
+}
+
+// NewMemoryCache creates a MemoryCache keeping at most maxEntries responses.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		log.Panicln("NewMemoryCache: maxEntries must be positive")
+	}
+	return &MemoryCache{
+		max:   maxEntries,
+		lru:   list.New(),
+		items: make(map[string]*list.Element),
+		tags:  make(map[string]map[string]struct{}),
+	}
+}
+
+// Get implements CacheStore.Get.
+func (p *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if e, ok := p.items[key]; ok {
+		p.lru.MoveToFront(e)
+		return e.Value.(*memCacheEntry).resp, true
+	}
+	return nil, false
+}
+
+// Set implements CacheStore.Set.
+func (p *MemoryCache) Set(key string, resp *CachedResponse) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.remove(key)
+	p.items[key] = p.lru.PushFront(&memCacheEntry{key: key, resp: resp})
+	for _, tag := range resp.Tags {
+		keys, ok := p.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			p.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	for p.lru.Len() > p.max {
+		p.remove(p.lru.Back().Value.(*memCacheEntry).key)
+	}
+}
+
+// Delete implements CacheStore.Delete.
+func (p *MemoryCache) Delete(key string) {
+	p.mutex.Lock()
+	p.remove(key)
+	p.mutex.Unlock()
+}
+
+// Purge implements CacheStore.Purge.
+func (p *MemoryCache) Purge(tags ...string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for _, tag := range tags {
+		for key := range p.tags[tag] {
+			p.remove(key)
+		}
+	}
+}
+
+// Len returns the number of responses kept.
+func (p *MemoryCache) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.lru.Len()
+}
+
+func (p *MemoryCache) remove(key string) {
+	e, ok := p.items[key]
+	if !ok {
+		return
+	}
+	p.lru.Remove(e)
+	delete(p.items, key)
+	for _, tag := range e.Value.(*memCacheEntry).resp.Tags {
+		if keys := p.tags[tag]; keys != nil {
+			delete(keys, key)
+			if len(keys) == 0 {
+				delete(p.tags, tag)
+			}
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// CacheOptions represents options of Cache.
+type CacheOptions struct {
+	// TTL is how long a response is fresh.
+	TTL time.Duration
+
+	// StaleWhileRevalidate is how long a response can be served stale after
+	// TTL, while it is revalidated in background.
+	StaleWhileRevalidate time.Duration
+
+	// Store is the storage of responses. It is NewMemoryCache(1024) by
+	// default. Set it to purge responses by tags.
+	Store CacheStore
+
+	// Query is the query parameters in the cache key. All parameters are in
+	// the key if it is nil.
+	Query []string
+
+	// Vary is the request headers in the cache key, eg. "Accept-Language".
+	Vary []string
+
+	// Tags is the tags of all responses. See Context.AddCacheTags.
+	Tags []string
+
+	// MaxBodySize is the max size of a response body to cache. It is 1 MB by
+	// default.
+	MaxBodySize int
+
+	// Now returns the current time. It is time.Now by default.
+	Now func() time.Time
+}
+
+// Cache returns a middleware which caches responses of GET and HEAD requests
+// on the server. Responses are keyed by method, host, path, query parameters
+// (see CacheOptions.Query) and request headers (see CacheOptions.Vary, and
+// the Vary header of responses).
+//
+// Requests with Authorization or Cookie headers bypass the cache, unless the
+// headers are in the key, ie. in CacheOptions.Vary or the Vary header of the
+// response (eg. "Vary: Authorization" of a per-user page). Requests with a CSP
+// nonce (see SecureHeaders) bypass the cache too.
+//
+// Concurrent requests of a missing response are coalesced, so the handler is
+// called once. Stale responses are served during StaleWhileRevalidate while a
+// fresh one is generated in background. Responses with Set-Cookie, "Vary: *",
+// "Cache-Control: private" or "no-store", responses with a CSP nonce (see
+// SecureHeaders), streaming responses (which flush or exceed MaxBodySize) and
+// responses of other status than 200, 203, 301, 404 and 410 are not cached. Responses have an "X-Cache" header of HIT, STALE or
+// MISS, eg.
+//
+//	store := yap.NewMemoryCache(1000)
+//	cached := y.With(yap.Cache(yap.CacheOptions{TTL: time.Minute, Store: store}))
+//	cached.GET("/articles/:id", func(ctx *yap.Context) {
+//		ctx.AddCacheTags("article:" + ctx.Param("id"))
+//		...
+//	})
+//	...
+//	store.Purge("article:" + id) // after the article is updated
+func Cache(opts CacheOptions) func(h http.Handler) http.Handler {
+	c := &responseCache{
+		CacheOptions: opts,
+		calls:        make(map[string]*cacheCall),
+		varies:       make(map[string][]string),
+	}
+	c.Vary = slices.Clone(c.Vary)
+	for i, name := range c.Vary {
+		c.Vary[i] = http.CanonicalHeaderKey(name)
+	}
+	if c.Store == nil {
+		c.Store = NewMemoryCache(1024)
+	}
+	if c.MaxBodySize <= 0 {
+		c.MaxBodySize = 1 << 20
+	}
+	if c.Now == nil {
+		c.Now = time.Now
+	}
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c.serve(h, w, r)
+		})
+	}
+}
+
+type responseCache struct {
+	CacheOptions
+	calls  map[string]*cacheCall // responses being generated
+	varies map[string][]string   // base key => Vary headers of the response
+	mutex  sync.Mutex
+}
+
+type cacheCall struct {
+	done chan struct{}
+	resp *CachedResponse // nil if not cacheable
+	key  string          // key of resp, by the Vary header of resp
+}
+
+type cacheTagsKey struct{}
+
+type cacheTags struct {
+	tags  []string
+	nonce bool // the response has a CSP nonce, see SecureHeaders
+}
+
+// AddCacheTags adds tags to the response being cached by the Cache
+// middleware, which can be purged by CacheStore.Purge later.
+func (p *Context) AddCacheTags(tags ...string) {
+	if ct, ok := p.Request.Context().Value(cacheTagsKey{}).(*cacheTags); ok {
+		ct.tags = append(ct.tags, tags...)
+	}
+}
+
+func (c *responseCache) serve(h http.Handler, w http.ResponseWriter, r *http.Request) {
+	if !isGetOrHead(r.Method) {
+		h.ServeHTTP(w, r)
+		return
+	}
+	base := c.baseKey(r)
+	key, shared := c.key(base, r, c.varyOf(base))
+	if !shared { // the response may be of the user
+		cw := &cacheWriter{w: w, header: make(http.Header), limit: c.MaxBodySize}
+		r2, tags := c.origin(r)
+		h.ServeHTTP(cw, r2)
+		if cw.stream {
+			return
+		}
+		if _, resp := c.save(base, r, cw, tags); resp != nil {
+			c.write(w, r, resp, "MISS")
+		} else {
+			cw.writeTo(w)
+		}
+		return
+	}
+	if resp, ok := c.Store.Get(key); ok {
+		now := c.Now()
+		switch {
+		case now.Before(resp.Expires):
+			c.write(w, r, resp, "HIT")
+			return
+		case now.Before(resp.StaleUntil):
+			c.revalidate(h, key, r)
+			c.write(w, r, resp, "STALE")
+			return
+		}
+		c.Store.Delete(key)
+	}
+
+	c.mutex.Lock()
+	if call, ok := c.calls[key]; ok { // coalesce with the request generating it
+		c.mutex.Unlock()
+		select {
+		case <-call.done:
+		case <-r.Context().Done():
+			return
+		}
+		if resp := call.resp; resp != nil && c.keyOf(base, r, resp) == call.key {
+			c.write(w, r, resp, "HIT")
+		} else {
+			h.ServeHTTP(w, r)
+		}
+		return
+	}
+	call := &cacheCall{done: make(chan struct{})}
+	c.calls[key] = call
+	c.mutex.Unlock()
+	defer c.finish(key, call)
+
+	cw := &cacheWriter{w: w, header: make(http.Header), limit: c.MaxBodySize}
+	r2, tags := c.origin(r)
+	h.ServeHTTP(cw, r2)
+	if cw.stream {
+		return
+	}
+	if call.key, call.resp = c.save(base, r, cw, tags); call.resp != nil {
+		c.write(w, r, call.resp, "MISS")
+	} else {
+		cw.writeTo(w)
+	}
+}
+
+// revalidate generates the response of key in background.
+func (c *responseCache) revalidate(h http.Handler, key string, r *http.Request) {
+	c.mutex.Lock()
+	if _, ok := c.calls[key]; ok {
+		c.mutex.Unlock()
+		return
+	}
+	call := &cacheCall{done: make(chan struct{})}
+	c.calls[key] = call
+	c.mutex.Unlock()
+
+	r = r.Clone(context.WithoutCancel(r.Context()))
+	go func() {
+		defer c.finish(key, call)
+		defer func() {
+			if e := recover(); e != nil {
+				log.Println("Cache: revalidate", key, "panic:", e)
+			}
+		}()
+		cw := &cacheWriter{header: make(http.Header), limit: c.MaxBodySize}
+		r2, tags := c.origin(r)
+		h.ServeHTTP(cw, r2)
+		call.key, call.resp = c.save(c.baseKey(r), r, cw, tags)
+	}()
+}
+
+func (c *responseCache) finish(key string, call *cacheCall) {
+	c.mutex.Lock()
+	delete(c.calls, key)
+	c.mutex.Unlock()
+	close(call.done)
+}
+
+// baseKey returns the key of r without request headers.
+func (c *responseCache) baseKey(r *http.Request) string {
+	var b strings.Builder
+	b.WriteString(r.Method)
+	b.WriteByte(' ')
+	b.WriteString(r.Host)
+	b.WriteString(r.URL.Path)
+	q := r.URL.Query()
+	if c.Query != nil {
+		sel := make(url.Values)
+		for _, k := range c.Query {
+			if v, ok := q[k]; ok {
+				sel[k] = v
+			}
+		}
+		q = sel
+	}
+	if len(q) > 0 {
+		b.WriteByte('?')
+		b.WriteString(q.Encode())
+	}
+	return b.String()
+}
+
+// key returns the key of r with headers in CacheOptions.Vary and vary (the
+// Vary header of the response). It reports whether the response can be shared
+// by requests of the key, which is false if r has credentials not in the key,
+// or a CSP nonce (see SecureHeaders) which differs in every response.
+func (c *responseCache) key(base string, r *http.Request, vary []string) (string, bool) {
+	names := append(slices.Clip(c.Vary), vary...)
+	var b strings.Builder
+	b.WriteString(base)
+	for _, name := range names {
+		b.WriteByte('\n')
+		b.WriteString(name)
+		b.WriteString(": ")
+		b.WriteString(strings.Join(r.Header.Values(name), ","))
+	}
+	for _, name := range []string{"Authorization", "Cookie"} {
+		if _, ok := r.Header[name]; ok && !slices.Contains(names, name) {
+			return b.String(), false
+		}
+	}
+	return b.String(), cspNonce(r) == ""
+}
+
+// keyOf returns the key of r by the Vary header of resp.
+func (c *responseCache) keyOf(base string, r *http.Request, resp *CachedResponse) string {
+	vary, _ := c.varyHeaders(resp.Header)
+	key, _ := c.key(base, r, vary)
+	return key
+}
+
+// varyOf returns the Vary header of the response of base cached last.
+func (c *responseCache) varyOf(base string) []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.varies[base]
+}
+
+// varyHeaders returns names in the Vary header of h, except those in
+// CacheOptions.Vary. ok is false if it has "*".
+func (c *responseCache) varyHeaders(h http.Header) (names []string, ok bool) {
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "*" {
+				return nil, false
+			}
+			if name != "" && !slices.Contains(c.Vary, name) && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names, true
+}
+
+// origin returns the request passed to the handler to generate a full
+// response: without conditional headers, and collecting cache tags.
+func (c *responseCache) origin(r *http.Request) (*http.Request, *cacheTags) {
+	tags := &cacheTags{tags: append([]string(nil), c.Tags...)}
+	r = r.WithContext(context.WithValue(r.Context(), cacheTagsKey{}, tags))
+	r.Header = r.Header.Clone()
+	r.Header.Del("If-None-Match")
+	r.Header.Del("If-Modified-Since")
+	return r, tags
+}
+
+// save caches the response of r generated in cw, and returns its key.
+func (c *responseCache) save(base string, r *http.Request, cw *cacheWriter, tags *cacheTags) (string, *CachedResponse) {
+	status := cw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if cw.stream || !cacheableStatus(status) || tags.nonce {
+		return "", nil
+	}
+	h := cw.header
+	if _, ok := h["Set-Cookie"]; ok {
+		return "", nil
+	}
+	if cc := h.Get("Cache-Control"); strings.Contains(cc, "no-store") || strings.Contains(cc, "private") {
+		return "", nil
+	}
+	vary, ok := c.varyHeaders(h)
+	if !ok {
+		return "", nil
+	}
+	c.mutex.Lock()
+	if vary != nil {
+		c.varies[base] = vary
+	} else {
+		delete(c.varies, base)
+	}
+	c.mutex.Unlock()
+	key, shared := c.key(base, r, vary)
+	if !shared {
+		return "", nil
+	}
+	if len(c.Vary) > 0 {
+		h.Set("Vary", strings.Join(append(slices.Clip(c.Vary), vary...), ", "))
+	}
+	now := c.Now()
+	expires := now.Add(c.TTL)
+	resp := &CachedResponse{
+		Status: status, Header: h, Body: cw.body.Bytes(), Tags: tags.tags,
+		Created: now, Expires: expires, StaleUntil: expires.Add(c.StaleWhileRevalidate),
+	}
+	c.Store.Set(key, resp)
+	return key, resp
+}
+
+func cacheableStatus(code int) bool {
+	switch code {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusMovedPermanently,
+		http.StatusNotFound, http.StatusGone:
+		return true
+	}
+	return false
+}
+
+func (c *responseCache) write(w http.ResponseWriter, r *http.Request, resp *CachedResponse, state string) {
+	h := w.Header()
+	for k, v := range resp.Header {
+		h[k] = append([]string(nil), v...)
+	}
+	h.Set("X-Cache", state)
+	if age := c.Now().Sub(resp.Created); age > 0 {
+		h.Set("Age", strconv.FormatInt(int64(age/time.Second), 10))
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		if inm := r.Header.Get("If-None-Match"); inm != "" && matchETag(inm, etag, weakMatch) {
+			delete(h, "Content-Type")
+			delete(h, "Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	h.Set("Content-Length", strconv.Itoa(len(resp.Body)))
+	w.WriteHeader(resp.Status)
+	if r.Method != http.MethodHead {
+		w.Write(resp.Body)
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// cacheWriter buffers a response to cache. It writes through to w (if not
+// nil) once the response turns out to be streaming.
+type cacheWriter struct {
+	w      http.ResponseWriter
+	header http.Header
+	status int
+	body   bytes.Buffer
+	limit  int
+	stream bool
+}
+
+func (p *cacheWriter) Header() http.Header {
+	if p.stream && p.w != nil {
+		return p.w.Header()
+	}
+	return p.header
+}
+
+func (p *cacheWriter) WriteHeader(code int) {
+	if p.status == 0 && code >= 200 {
+		p.status = code
+	}
+	if p.stream && p.w != nil {
+		p.w.WriteHeader(code)
+	}
+}
+
+func (p *cacheWriter) Write(b []byte) (int, error) {
+	if p.status == 0 {
+		p.WriteHeader(http.StatusOK)
+	}
+	if !p.stream && p.body.Len()+len(b) > p.limit {
+		p.startStream()
+	}
+	if p.stream {
+		if p.w == nil {
+			return len(b), nil
+		}
+		return p.w.Write(b)
+	}
+	return p.body.Write(b)
+}
+
+func (p *cacheWriter) Flush() {
+	if !p.stream {
+		p.startStream()
+	}
+	if f, ok := p.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// startStream stops buffering, and writes what is buffered to w.
+func (p *cacheWriter) startStream() {
+	p.stream = true
+	if p.w != nil {
+		p.writeTo(p.w)
+	}
+}
+
+func (p *cacheWriter) writeTo(w http.ResponseWriter) {
+	h := w.Header()
+	for k, v := range p.header {
+		h[k] = v
+	}
+	status := p.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write(p.body.Bytes())
+}
+
+// -----------------------------------------------------------------------------
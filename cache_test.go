@@ -0,0 +1,259 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goplus/yap"
+)
+
+func TestCache(t *testing.T) {
+	clock := newClock()
+	store := yap.NewMemoryCache(10)
+	e := yap.New()
+	e.UseETag()
+	var calls atomic.Int32
+	generated := make(chan struct{}, 10)
+	cached := e.With(yap.Cache(yap.CacheOptions{
+		TTL: time.Minute, StaleWhileRevalidate: time.Hour, Store: store,
+		Query: []string{"page"}, Vary: []string{"Accept-Language"}, Now: clock.Now,
+	}))
+	cached.GET("/articles", func(ctx *yap.Context) {
+		n := calls.Add(1)
+		ctx.AddCacheTags("articles")
+		ctx.TEXT(200, "text/plain", "v"+strconv.Itoa(int(n))+" "+ctx.Param("page"))
+		generated <- struct{}{}
+	})
+
+	get := func(path string, header ...string) (string, string) {
+		t.Helper()
+		w := serveWith(e, "GET", path, header...)
+		if w.Code != 200 {
+			t.Fatal("GET", path, w.Code)
+		}
+		return w.Header().Get("X-Cache"), w.Body.String()
+	}
+	if state, body := get("/articles?page=1&utm=a"); state != "MISS" || body != "v1 1" {
+		t.Fatal("first:", state, body)
+	}
+	<-generated
+	clock.Add(time.Second)
+	w := serveWith(e, "GET", "/articles?utm=b&page=1")
+	if w.Header().Get("X-Cache") != "HIT" || w.Body.String() != "v1 1" || w.Header().Get("Age") != "1" {
+		t.Fatal("hit:", w.Header(), w.Body.String())
+	}
+	if w = serveWith(e, "GET", "/articles?page=1", "If-None-Match", w.Header().Get("ETag")); w.Code != 304 {
+		t.Fatal("If-None-Match:", w.Code)
+	}
+	if state, _ := get("/articles?page=2"); state != "MISS" {
+		t.Fatal("query:", state)
+	}
+	<-generated
+	if state, _ := get("/articles?page=1", "Accept-Language", "fr"); state != "MISS" {
+		t.Fatal("vary:", state)
+	}
+	<-generated
+
+	clock.Add(2 * time.Minute)
+	if state, body := get("/articles?page=1"); state != "STALE" || body != "v1 1" {
+		t.Fatal("stale:", state, body)
+	}
+	<-generated // revalidated in background
+	for {
+		state, body := get("/articles?page=1")
+		if state == "HIT" {
+			if body != "v4 1" {
+				t.Fatal("revalidated:", body)
+			}
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	store.Purge("articles")
+	if store.Len() != 0 {
+		t.Fatal("Purge:", store.Len())
+	}
+	if state, _ := get("/articles?page=1"); state != "MISS" {
+		t.Fatal("purged:", state)
+	}
+	<-generated
+}
+
+func TestCacheCSPNonce(t *testing.T) {
+	csp := yap.SecureHeadersOptions{CSP: "script-src 'nonce-{nonce}'"}
+	page := func(ctx *yap.Context) {
+		ctx.TEXT(200, "text/html", `<script nonce="`+ctx.CSPNonce()+`"></script>`)
+	}
+	outer := yap.New() // SecureHeaders before Cache
+	outer.UseSecureHeaders(csp)
+	outer.With(yap.Cache(yap.CacheOptions{TTL: time.Minute})).GET("/", page)
+	inner := yap.New() // SecureHeaders after Cache
+	inner.With(yap.Cache(yap.CacheOptions{TTL: time.Minute}), yap.SecureHeaders(csp)).GET("/", page)
+	for _, e := range []*yap.Engine{outer, inner} {
+		var last string
+		for range 2 {
+			w := serveWith(e, "GET", "/")
+			body := w.Body.String()
+			nonce := strings.TrimSuffix(strings.TrimPrefix(body, `<script nonce="`), `"></script>`)
+			if nonce == "" || body == last || w.Header().Get("X-Cache") == "HIT" ||
+				!strings.Contains(w.Header().Get("Content-Security-Policy"), "'nonce-"+nonce+"'") {
+				t.Fatal("nonce replayed:", w.Header(), body)
+			}
+			last = body
+		}
+	}
+}
+
+func TestCacheCoalescing(t *testing.T) {
+	e := yap.New()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	e.With(yap.Cache(yap.CacheOptions{TTL: time.Minute})).GET("/slow", func(ctx *yap.Context) {
+		calls.Add(1)
+		<-release
+		ctx.TEXT(200, "text/plain", "done")
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if w := serveWith(e, "GET", "/slow"); w.Body.String() != "done" {
+				t.Error("GET /slow:", w.Body.String())
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatal("calls:", n)
+	}
+}
+
+func TestCacheExcluded(t *testing.T) {
+	e := yap.New()
+	var calls atomic.Int32
+	cached := e.With(yap.Cache(yap.CacheOptions{TTL: time.Minute}))
+	cached.GET("/stream", func(ctx *yap.Context) {
+		calls.Add(1)
+		ctx.STREAM(200, "text/plain", strings.NewReader("stream"), make([]byte, 2))
+	})
+	cached.GET("/cookie", func(ctx *yap.Context) {
+		calls.Add(1)
+		ctx.SetCookie(&http.Cookie{Name: "a", Value: "b"})
+		ctx.TEXT(200, "text/plain", "cookie")
+	})
+	cached.GET("/error", func(ctx *yap.Context) {
+		calls.Add(1)
+		ctx.TEXT(500, "text/plain", "error")
+	})
+	for _, path := range []string{"/stream", "/cookie", "/error"} {
+		calls.Store(0)
+		for i := 0; i < 2; i++ {
+			w := serveWith(e, "GET", path)
+			if w.Header().Get("X-Cache") == "HIT" || w.Body.String() != path[1:] {
+				t.Fatal(path, w.Header(), w.Body.String())
+			}
+		}
+		if calls.Load() != 2 {
+			t.Fatal(path, "calls:", calls.Load())
+		}
+	}
+}
+
+func TestCacheVary(t *testing.T) {
+	e := yap.New()
+	tokens := yap.StaticTokens(map[string]string{"t1": "alice", "t2": "bob"})
+	cached := e.With(yap.Cache(yap.CacheOptions{TTL: time.Minute}), yap.Auth(yap.AuthOptions{}, yap.BearerAuth(tokens)))
+	var calls atomic.Int32
+	user := func(ctx *yap.Context) string {
+		calls.Add(1)
+		if claims := ctx.Claims(); claims != nil {
+			return claims.Subject()
+		}
+		return "anonymous"
+	}
+	cached.GET("/me", func(ctx *yap.Context) {
+		ctx.ResponseWriter.Header().Set("Vary", "Authorization")
+		ctx.Text__2("hello " + user(ctx))
+	})
+	cached.GET("/feed", func(ctx *yap.Context) {
+		ctx.Text__2("feed of " + user(ctx))
+	})
+	cached.GET("/any", func(ctx *yap.Context) {
+		ctx.ResponseWriter.Header().Set("Vary", "*")
+		ctx.Text__2("any " + user(ctx))
+	})
+	cached.GET("/lang", func(ctx *yap.Context) {
+		ctx.ResponseWriter.Header().Set("Vary", "accept-language")
+		calls.Add(1)
+		ctx.Text__2("lang " + ctx.Request.Header.Get("Accept-Language"))
+	})
+
+	check := func(path, state, body string, header ...string) {
+		t.Helper()
+		w := serveWith(e, "GET", path, header...)
+		if w.Code != 200 || w.Header().Get("X-Cache") != state || w.Body.String() != body {
+			t.Fatal("GET", path, header, w.Code, w.Header().Get("X-Cache"), w.Body.String())
+		}
+	}
+	for i, state := range []string{"MISS", "HIT"} {
+		check("/me", state, "hello alice", "Authorization", "Bearer t1")
+		check("/me", state, "hello bob", "Authorization", "Bearer t2")
+		if i == 0 {
+			check("/me", "MISS", "hello anonymous")
+		} else {
+			check("/me", "HIT", "hello anonymous")
+		}
+	}
+	if n := calls.Swap(0); n != 3 {
+		t.Fatal("/me calls:", n)
+	}
+
+	// credentials not in the key bypass the cache
+	check("/feed", "MISS", "feed of anonymous")
+	check("/feed", "", "feed of alice", "Authorization", "Bearer t1")
+	check("/feed", "", "feed of bob", "Authorization", "Bearer t2")
+	check("/feed", "", "feed of anonymous", "Cookie", "session=x")
+	check("/feed", "HIT", "feed of anonymous")
+	if n := calls.Swap(0); n != 4 {
+		t.Fatal("/feed calls:", n)
+	}
+
+	check("/any", "", "any anonymous")
+	check("/any", "", "any anonymous")
+	if n := calls.Swap(0); n != 2 {
+		t.Fatal("Vary: * cached:", n)
+	}
+
+	check("/lang", "MISS", "lang en", "Accept-Language", "en")
+	check("/lang", "MISS", "lang fr", "Accept-Language", "fr")
+	check("/lang", "HIT", "lang en", "Accept-Language", "en")
+	check("/lang", "HIT", "lang fr", "Accept-Language", "fr")
+	if n := calls.Swap(0); n != 2 {
+		t.Fatal("/lang calls:", n)
+	}
+}
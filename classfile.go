@@ -89,6 +89,11 @@ func (p *App) Static__2(pattern string, fs http.FileSystem, allowRedirect ...boo
 	p.StaticHttp(pattern, fs, allowRedirect...)
 }
 
+// Static serves static files from a http file system with options.
+func (p *App) Static__3(pattern string, fs http.FileSystem, opts StaticOptions) {
+	p.StaticWith(pattern, fs, opts)
+}
+
 // AppType represents an abstract of YAP applications.
 type AppType interface {
 	InitYap(fs ...fs.FS)
@@ -195,6 +200,44 @@ func (p *Context) Yap__1(yapFile string, data any) {
 	p.YAP(200, yapFile, data)
 }
 
+// Reply__0 replies to the request with a value as the result of a handler,
+// eg. in post_articles.yap:
+//
+//	reply 201, article
+//
+// See Context.Reply.
+func (p *Context) Reply__0(code int, v any) {
+	p.Reply(code, v)
+}
+
+// Reply__1 replies to the request with a value as the result of a handler,
+// eg. in get_p_#id.yap:
+//
+//	article, err := getArticle(${id})
+//	if err != nil {
+//		reply err
+//		return
+//	}
+//	reply article
+func (p *Context) Reply__1(v any) {
+	p.Reply(http.StatusOK, v)
+}
+
+// Upload__0 saves the file uploaded in the form field name into dir, and
+// returns the path saved, eg. in post_upload.yap:
+//
+//	json {"path": upload("file", "uploads")!}
+//
+// If it fails, the request is replied with a 413 or 400 error.
+func (p *Context) Upload__0(name, dir string) (path string, err error) {
+	return p.upload(name, dir)
+}
+
+// Upload__1 saves the file uploaded in the form field "file" into dir.
+func (p *Context) Upload__1(dir string) (path string, err error) {
+	return p.upload("file", dir)
+}
+
 func (p *Context) Stream__0(code int, mime string, read io.Reader, buf []byte) {
 	p.STREAM(code, mime, read, buf)
 }
@@ -17,7 +17,11 @@
 package yap_test
 
 import (
+	"fmt"
+	"io/fs"
 	"net/http"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/goplus/yap"
@@ -68,3 +72,357 @@ func TestClassfile(t *testing.T) {
 	}
 	defer resp.Body.Close()
 }
+
+type GrammarAppV2 struct {
+	yap.AppV2
+}
+
+func (p *GrammarAppV2) Main() {
+	h := func(fname string) *grammarHandler {
+		return &grammarHandler{fname: fname}
+	}
+	yap.XGot_AppV2_Main(p,
+		h("get"), h("get_p_#id@article"), h("get_q_:id"), h("get_files_*path"), h("get_blog_index"), h("get_legacy_"),
+		h("get_user__info"), h("get_a-b_c%2Dd"), h("any_api_#ver"), h("handle_static_index"),
+	)
+}
+
+type BadNamesAppV2 struct {
+	yap.AppV2
+}
+
+func (p *BadNamesAppV2) Main() {
+	h := func(fname string) *grammarHandler {
+		return &grammarHandler{fname: fname}
+	}
+	yap.XGot_AppV2_Main(p,
+		h("get"), h("get_*path_x"), h("get_#"), h("handle_#id"),
+		h("get_a:b"), h("get_x@"), h("g3t_x"), h("api/*x/get"), h("api//get"), h("#org/handle_x"),
+		h("every_soon"), h("cron_1_2"), h("every"),
+	)
+}
+
+type grammarHandler struct {
+	yap.Handler
+	yap.AppType
+	fname string
+}
+
+func (p *grammarHandler) Main(ctx *yap.Context) {
+	p.Handler.Main(ctx)
+	ctx.Text__2("ok")
+}
+
+func (p *grammarHandler) Classfname() string {
+	return p.fname
+}
+
+func (p *grammarHandler) Classclone() yap.HandlerProto {
+	ret := *p
+	return &ret
+}
+
+func TestClassfnameGrammar(t *testing.T) {
+	app := new(GrammarAppV2)
+	app.InitYap()
+	app.SetLAS(func(addr string, h http.Handler) error { return nil })
+	app.Main()
+	var routes []string
+	for _, r := range app.Routes() {
+		routes = append(routes, r.String())
+	}
+	const want = "GET / | GET /p/:id @article | GET /q/:id | GET /files/*path | GET /blog/ | GET /legacy/ | GET /user_info | GET /a-b/c-d | " +
+		"GET /api/:ver | HEAD /api/:ver | POST /api/:ver | PUT /api/:ver | PATCH /api/:ver | DELETE /api/:ver | OPTIONS /api/:ver | " +
+		"* /static/"
+	if got := strings.Join(routes, " | "); got != want {
+		t.Fatal("Routes:", got)
+	}
+	for _, c := range []struct{ method, path string }{
+		{"GET", "/files/a/b.txt"}, {"GET", "/blog/"}, {"GET", "/legacy/"}, {"GET", "/user_info"},
+		{"DELETE", "/api/v1"}, {"GET", "/static/x"},
+	} {
+		if code := serveAs(app, c.method, c.path, ""); code != 200 {
+			t.Fatal(c.method, c.path, code)
+		}
+	}
+}
+
+func TestClassfnameErrors(t *testing.T) {
+	defer func() {
+		e := recover()
+		msg, _ := e.(string)
+		for _, want := range []string{
+			`invalid handler file name "get_*path_x": catch-all param *path must be the last segment`,
+			`invalid handler file name "get_#": invalid param name ""`,
+			`invalid handler file name "handle_#id": params are not supported by handle`,
+			`invalid handler file name "get_a:b": '#', ':', '*' and '/' are not allowed in literal segment "a:b"`,
+			`invalid handler file name "get_x@": empty route name after '@'`,
+			`invalid handler file name "g3t_x": method must be letters`,
+			`invalid handler file name "api/*x/get": catch-all param *x is not allowed in directories`,
+			`invalid handler file name "api//get": empty path segment`,
+			`invalid handler file name "#org/handle_x": params are not supported by handle`,
+			`invalid handler file name "every_soon": bad interval "soon"`,
+			`invalid handler file name "cron_1_2": cron: expected 5 fields in "1 2"`,
+			`invalid handler file name "every": every needs a schedule`,
+		} {
+			if !strings.Contains(msg, want) {
+				t.Fatalf("%s not found in:\n%v", want, e)
+			}
+		}
+	}()
+	app := new(BadNamesAppV2)
+	app.InitYap()
+	app.Main()
+	t.Fatal("XGot_AppV2_Main: should panic")
+}
+
+type DirAppV2 struct {
+	yap.AppV2
+}
+
+func (p *DirAppV2) Main() {
+	h := func(fname string) *grammarHandler {
+		return &grammarHandler{fname: fname}
+	}
+	yap.XGot_AppV2_Main(p,
+		h("get"), h("api/users/get_#id@user"), h("api/users/get"), h("api/#org/post_repos"),
+		h("api/files/handle_index"), h("public/get_index"),
+		&dirMiddleware{fname: "api/_middleware", policies: []*yap.Policy{yap.RequireAuth()}},
+		&dirMiddleware{fname: "api/users/_middleware"},
+	)
+}
+
+// dirMiddleware denies requests with a X-Block header, and sets the X-Dir
+// header to directories of middlewares it passes.
+type dirMiddleware struct {
+	yap.Handler
+	yap.AppType
+	fname    string
+	policies []*yap.Policy
+}
+
+func (p *dirMiddleware) Main(ctx *yap.Context) {
+	p.Handler.Main(ctx)
+	if ctx.Request.Header.Get("X-Block") == p.fname {
+		ctx.TEXT(403, "text/plain", "blocked")
+	}
+}
+
+func (p *dirMiddleware) Policies() []*yap.Policy {
+	return p.policies
+}
+
+func (p *dirMiddleware) Middlewares() []func(h http.Handler) http.Handler {
+	return []func(h http.Handler) http.Handler{
+		func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Dir", p.fname)
+				h.ServeHTTP(w, r)
+			})
+		},
+	}
+}
+
+func (p *dirMiddleware) Classfname() string {
+	return p.fname
+}
+
+func (p *dirMiddleware) Classclone() yap.HandlerProto {
+	ret := *p
+	return &ret
+}
+
+func TestClassfnameDirs(t *testing.T) {
+	app := new(DirAppV2)
+	app.InitYap()
+	app.SetLAS(func(addr string, h http.Handler) error { return nil })
+	app.Use(yap.Auth(yap.AuthOptions{}, users))
+	app.Main()
+	var routes []string
+	for _, r := range app.Routes() {
+		routes = append(routes, r.String())
+	}
+	const want = "GET / | GET /api/users/:id [authenticated] @user | GET /api/users [authenticated] | " +
+		"POST /api/:org/repos [authenticated] | * /api/files/ [authenticated] | GET /public/"
+	if got := strings.Join(routes, " | "); got != want {
+		t.Fatal("Routes:", got)
+	}
+
+	if code := serveAs(app, "GET", "/api/users/1", ""); code != 401 {
+		t.Fatal("anonymous:", code)
+	}
+	for _, path := range []string{"/api/users/1", "/api/users", "/api/files/x", "/public/"} {
+		if code := serveAs(app, "GET", path, "reader"); code != 200 {
+			t.Fatal(path, code)
+		}
+	}
+	w := serveWith(app, "GET", "/api/users/1", "X-User", "reader")
+	if got := w.Header().Values("X-Dir"); strings.Join(got, ",") != "api/_middleware,api/users/_middleware" {
+		t.Fatal("X-Dir:", got)
+	}
+	for _, fname := range []string{"api/_middleware", "api/users/_middleware"} {
+		w = serveWith(app, "GET", "/api/users/1", "X-User", "reader", "X-Block", fname)
+		if w.Code != 403 || w.Body.String() != "blocked" {
+			t.Fatal("X-Block:", fname, w.Code, w.Body.String())
+		}
+	}
+	if w = serveWith(app, "GET", "/api/users", "X-User", "reader", "X-Block", "api/_middleware"); w.Code != 403 {
+		t.Fatal("X-Block:", w.Code)
+	}
+	if w = serveWith(app, "POST", "/api/o/repos", "X-User", "reader", "X-Block", "api/users/_middleware"); w.Code != 200 {
+		t.Fatal("POST /api/o/repos:", w.Code)
+	}
+
+	var b strings.Builder
+	if err := app.PrintRoutes(&b); err != nil {
+		t.Fatal(err)
+	}
+	if table := b.String(); !strings.HasPrefix(table, "METHOD  PATH") ||
+		!strings.Contains(table, "GET     /api/users/:id   user  api/users/get_#id@user.yap  authenticated\n") {
+		t.Fatal("PrintRoutes:\n" + table)
+	}
+}
+
+type SessionAppV2 struct {
+	yap.AppV2
+}
+
+func (p *SessionAppV2) Main() {
+	yap.XGot_AppV2_Main(p, &sessionHandler{fname: "_middleware"}, &sessionHandler{fname: "get"})
+}
+
+// sessionHandler sets a session value named by its file name, and the handler
+// (not the _middleware) replies the values set.
+type sessionHandler struct {
+	yap.Handler
+	yap.AppType
+	fname string
+}
+
+func (p *sessionHandler) Main(ctx *yap.Context) {
+	p.Handler.Main(ctx)
+	sess := p.Session() // of the copy of ctx
+	sess.Set(p.fname, "1")
+	if p.fname == "get" {
+		_, ok := sess.Get("_middleware").(string)
+		p.TEXT(200, "text/plain", strconv.FormatBool(ok))
+	}
+}
+
+func (p *sessionHandler) Classfname() string {
+	return p.fname
+}
+
+func (p *sessionHandler) Classclone() yap.HandlerProto {
+	ret := *p
+	return &ret
+}
+
+func TestClassfileSession(t *testing.T) {
+	store := yap.NewMemoryStore()
+	app := new(SessionAppV2)
+	app.InitYap()
+	app.SetLAS(func(addr string, h http.Handler) error { return nil })
+	app.UseSession(store)
+	app.Main()
+	w := serve(app, "GET", "/")
+	if w.Body.String() != "true" {
+		t.Fatal("session not shared with the _middleware:", w.Body.String())
+	}
+	if n := len(w.Result().Cookies()); n != 1 {
+		t.Fatal("session cookies:", n)
+	}
+	if n := store.Len(); n != 1 {
+		t.Fatal("sessions saved:", n)
+	}
+}
+
+// CustomApp implements yap.AppType by itself, rather than embedding yap.AppV2.
+type CustomApp struct {
+	e    *yap.Engine
+	las  func(addr string, h http.Handler) error
+	name string
+}
+
+func (p *CustomApp) InitYap(fs ...fs.FS) {
+	if p.e == nil {
+		p.e = yap.New(fs...)
+	}
+}
+
+func (p *CustomApp) SetLAS(las func(addr string, h http.Handler) error) {
+	p.las = las
+}
+
+func (p *CustomApp) ProtoRoute(method, path string, proto yap.HandlerProto) {
+	p.e.ProtoRoute(method, path, proto)
+}
+
+func (p *CustomApp) ProtoHandle(pattern string, proto yap.HandlerProto) {
+	p.e.ProtoHandle(pattern, proto)
+}
+
+func (p *CustomApp) Run(addr string, mws ...func(h http.Handler) http.Handler) error {
+	return p.las(addr, p.e.Handler(mws...))
+}
+
+type customHandler struct {
+	yap.Handler
+	*CustomApp
+	fname string
+}
+
+func (p *customHandler) Main(ctx *yap.Context) {
+	p.Handler.Main(ctx)
+	ctx.Text__2("hi " + ctx.Param("name") + " from " + p.CustomApp.name)
+}
+
+func (p *customHandler) Classfname() string {
+	return p.fname
+}
+
+func (p *customHandler) Classclone() yap.HandlerProto {
+	ret := *p
+	return &ret
+}
+
+func TestCustomAppType(t *testing.T) {
+	app := &CustomApp{name: "custom"}
+	var addr string
+	var handler http.Handler
+	app.SetLAS(func(a string, h http.Handler) error {
+		addr, handler = a, h
+		return nil
+	})
+	yap.XGot_AppV2_Main(app, &customHandler{fname: "get_hi_#name"}, &customHandler{fname: "any_echo"})
+	if addr != "localhost:8080" {
+		t.Fatal("addr:", addr)
+	}
+	if w := serveWith(handler, "GET", "/hi/ken"); w.Body.String() != "hi ken from custom" {
+		t.Fatal("GET /hi/ken:", w.Code, w.Body.String())
+	}
+	if w := serveWith(handler, "DELETE", "/echo"); w.Code != 200 {
+		t.Fatal("DELETE /echo:", w.Code)
+	}
+
+	defer func() {
+		if e := recover(); !strings.Contains(fmt.Sprint(e), "yap: every_1s requires an app based on yap.AppV2") {
+			t.Fatal("recover:", e)
+		}
+	}()
+	yap.XGot_AppV2_Main(&CustomApp{}, &customHandler{fname: "every_1s"})
+}
+
+type NoAppFieldAppV2 struct {
+	yap.AppV2
+}
+
+func TestSetAppFieldPanic(t *testing.T) {
+	defer func() {
+		if e := recover(); !strings.Contains(fmt.Sprint(e), "yap: handler get has no exported field of app type *yap_test.NoAppFieldAppV2") {
+			t.Fatal("recover:", e)
+		}
+	}()
+	// handlerV2 refers to *AppV2, not *NoAppFieldAppV2
+	yap.XGot_AppV2_Main(new(NoAppFieldAppV2), &handlerV2{fname: "get"})
+}
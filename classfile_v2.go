@@ -17,9 +17,16 @@
 package yap
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"net/url"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // HandlerProto is the prototype of a YAP handler.
@@ -30,20 +37,19 @@ type HandlerProto interface {
 
 // ProtoHandle registers a YAP handler with a prototype.
 func (p *Engine) ProtoHandle(pattern string, proto HandlerProto) {
-	p.Mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
-		// ensure isolation of handler state per request
-		h := proto.Classclone()
-		h.Main(p.NewContext(w, r))
-	})
+	p.Handle(pattern, protoHandle(proto))
 }
 
 // ProtoRoute registers a YAP handler with a prototype.
 func (p *Engine) ProtoRoute(method, path string, proto HandlerProto) {
-	p.Route(method, path, func(ctx *Context) {
+	p.Route(method, path, protoHandle(proto))
+}
+
+func protoHandle(proto HandlerProto) func(ctx *Context) {
+	return func(ctx *Context) {
 		// ensure isolation of handler state per request
-		h := proto.Classclone()
-		h.Main(ctx)
-	})
+		runProto(ctx, proto)
+	}
 }
 
 // Handler is worker class of YAP classfile (v2).
@@ -56,16 +62,206 @@ func (p *Handler) Main(ctx *Context) {
 	p.Context = *ctx
 }
 
-var (
-	repl = strings.NewReplacer("_", "/", "#", ":")
-)
+// classRoute represents a route declared by the file name of a handler.
+type classRoute struct {
+	method     string // "handle" for Engine.Mux patterns, "any" for all methods
+	path       string
+	name       string
+	dir        string // directory of the handler file, eg. "api/users"
+	middleware bool   // a _middleware handler of dir
+	schedule   string // interval of "every" jobs, or cron expression of "cron" jobs
+}
+
+// classMiddleware is the file name of a directory middleware handler.
+const classMiddleware = "_middleware"
 
-func parseClassfname(name string) (method, path string) {
-	pos := strings.IndexByte(name, '_')
-	if pos < 0 {
-		return name, "/"
+// parseClassfname parses the file name (without extension) of a handler. Its
+// grammar is:
+//
+//	[dir/...]method[_segment...][@name]
+//
+// where method is a HTTP method (eg. get), "any" for all methods, or "handle"
+// for a pattern of Engine.Mux, and segments are separated by "_":
+//   - #name (or :name) is a param, eg. get_p_#id => GET /p/:id
+//   - *name is a catch-all param (only as the last segment), eg.
+//     get_files_*path => GET /files/*path
+//   - index as the last segment means a trailing slash, eg. get_blog_index =>
+//     GET /blog/ (a trailing _ as get_blog_ means it too, which is deprecated)
+//   - __ is a literal underscore, eg. get_user__info => GET /user_info
+//   - %XX is an escaped character, eg. get_a%2Db => GET /a-b (a dash needs no
+//     escaping though: get_a-b => GET /a-b)
+//
+// and @name is the route name, eg. get_p_#id@article.
+//
+// Methods "every" and "cron" declare background jobs (see Engine.Every and
+// Engine.Cron) instead of routes, eg. every_10m@cleanup runs every 10 minutes,
+// and cron_0_3_*_*_* (or cron_daily for "@daily") runs at 03:00 every day.
+// Fields of cron are separated by "_", and "/" in them is escaped as %2F.
+//
+// Each directory is a
+// path segment (where "_" is literal) before those of the file name, eg.
+// api/users/get_#id => GET /api/users/:id, and api/users/get => GET
+// /api/users. A handler named dir/_middleware runs before all handlers in dir
+// and its subdirectories.
+func parseClassfname(fname string) (ret classRoute, err error) {
+	fail := func(reason string) (classRoute, error) {
+		return classRoute{}, fmt.Errorf("yap: invalid handler file name %q: %s", fname, reason)
+	}
+	name := fname
+	var prefix string
+	if pos := strings.LastIndexByte(name, '/'); pos >= 0 {
+		ret.dir, name = name[:pos], name[pos+1:]
+		for _, seg := range strings.Split(ret.dir, "/") {
+			if seg != "" && seg[0] == '*' {
+				return fail("catch-all param " + seg + " is not allowed in directories")
+			}
+			if seg, err = classSegment(seg); err != nil {
+				return fail(err.Error())
+			}
+			prefix += "/" + seg
+		}
+	}
+	if name == classMiddleware {
+		ret.middleware = true
+		return
+	}
+	if pos := strings.LastIndexByte(name, '@'); pos >= 0 {
+		if ret.name = name[pos+1:]; ret.name == "" {
+			return fail("empty route name after '@'")
+		}
+		name = name[:pos]
+	}
+	method, rest, found := strings.Cut(name, "_")
+	if !isClassIdent(method, false) {
+		return fail("method must be letters, eg. get, post, any or handle")
 	}
-	return name[:pos], repl.Replace(name[pos:])
+	ret.method = method
+	if m := strings.ToLower(method); m == "every" || m == "cron" {
+		if !found {
+			return fail(m + " needs a schedule, eg. every_5m or cron_0_3_*_*_*")
+		}
+		if ret.schedule, err = jobSchedule(m, rest); err != nil {
+			return fail(err.Error())
+		}
+		return
+	}
+	if method == "handle" && strings.ContainsAny(prefix, ":") {
+		return fail("params are not supported by handle")
+	}
+	if !found {
+		if ret.path = prefix; prefix == "" {
+			ret.path = "/"
+		}
+		return
+	}
+	var segs []string
+	var seg []byte
+	for i := 0; i < len(rest); i++ {
+		if c := rest[i]; c != '_' {
+			seg = append(seg, c)
+		} else if i+1 < len(rest) && rest[i+1] == '_' { // __ => literal _
+			seg = append(seg, '_')
+			i++
+		} else {
+			segs = append(segs, string(seg))
+			seg = nil
+		}
+	}
+	segs = append(segs, string(seg))
+	last := len(segs) - 1
+	for i, seg := range segs {
+		switch {
+		case seg != "" && (seg[0] == '#' || seg[0] == ':' || seg[0] == '*'):
+			if method == "handle" {
+				return fail("params are not supported by handle")
+			}
+			if seg[0] == '*' {
+				if i != last {
+					return fail("catch-all param " + seg + " must be the last segment")
+				}
+				if !isClassIdent(seg[1:], true) {
+					return fail("invalid param name " + strconv.Quote(seg[1:]))
+				}
+				continue
+			}
+		case seg == "index" && i == last:
+			segs[i] = ""
+			continue
+		case seg == "" && i == last: // deprecated trailing _, see index
+			continue
+		}
+		if segs[i], err = classSegment(seg); err != nil {
+			return fail(err.Error())
+		}
+	}
+	ret.path = prefix + "/" + strings.Join(segs, "/")
+	return
+}
+
+// jobSchedule returns the schedule of a job declared by the file name.
+func jobSchedule(method, rest string) (string, error) {
+	if method == "every" {
+		if d, err := time.ParseDuration(rest); err != nil || d <= 0 {
+			return "", errors.New("bad interval " + strconv.Quote(rest))
+		}
+		return rest, nil
+	}
+	fields := strings.Split(rest, "_")
+	for i, f := range fields {
+		v, err := url.PathUnescape(f)
+		if err != nil {
+			return "", errors.New("invalid escape in cron field " + strconv.Quote(f))
+		}
+		fields[i] = v
+	}
+	spec := strings.Join(fields, " ")
+	if len(fields) == 1 {
+		spec = "@" + spec
+	}
+	if _, err := parseCron(spec); err != nil {
+		return "", err
+	}
+	return spec, nil
+}
+
+// classSegment converts a path segment (except a catch-all param) of a handler
+// file name to that of a route.
+func classSegment(seg string) (string, error) {
+	switch {
+	case seg == "":
+		return "", errors.New("empty path segment")
+	case seg[0] == '#' || seg[0] == ':':
+		if !isClassIdent(seg[1:], true) {
+			return "", errors.New("invalid param name " + strconv.Quote(seg[1:]))
+		}
+		return ":" + seg[1:], nil
+	}
+	lit, err := url.PathUnescape(seg)
+	if err != nil {
+		return "", errors.New("invalid escape in segment " + strconv.Quote(seg))
+	}
+	if strings.ContainsAny(lit, "#:*/") {
+		return "", errors.New("'#', ':', '*' and '/' are not allowed in literal segment " + strconv.Quote(seg))
+	}
+	return lit, nil
+}
+
+func isClassIdent(s string, allowDigits bool) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || allowDigits && ('0' <= c && c <= '9' || c == '_')) {
+			return false
+		}
+	}
+	return true
+}
+
+// anyMethods are methods of routes declared by "any" handlers.
+var anyMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
 }
 
 // AppV2 is project class of YAP classfile (v2).
@@ -78,21 +274,240 @@ type iHandlerProto interface {
 	Classfname() string
 }
 
+type protoRouter interface {
+	ProtoRoute(method, path string, proto HandlerProto)
+	ProtoHandle(pattern string, proto HandlerProto)
+}
+
+// A YAP handler can declare authorization policies of its route by defining a
+// Policies method, eg.
+//
+//	func Policies() []*yap.Policy {
+//		return [yap.RequireRoles("admin")]
+//	}
+type iHandlerPolicies interface {
+	Policies() []*Policy
+}
+
+func (p *Engine) engine() *Engine {
+	return p
+}
+
+// A _middleware handler can declare middlewares of its directory by defining a
+// Middlewares method, eg.
+//
+//	func Middlewares() []func(h http.Handler) http.Handler {
+//		return [yap.BodyLimit(1 << 20)]
+//	}
+type iHandlerMiddlewares interface {
+	Middlewares() []func(h http.Handler) http.Handler
+}
+
+// classDir represents settings of a handler directory, which are inherited by
+// its subdirectories.
+type classDir struct {
+	group *Group
+	hooks []HandlerProto // _middleware handlers, outermost first
+}
+
+// hookedProto runs _middleware handlers before a handler. A _middleware
+// handler stops the request by writing a response, or by returning false from
+// its Before method.
+type hookedProto struct {
+	hooks []HandlerProto
+	proto HandlerProto
+}
+
+func (p *hookedProto) Main(ctx *Context) {
+	w := wrapResponse(ctx.ResponseWriter)
+	ctx.ResponseWriter = w
+	for _, hook := range p.hooks {
+		if !runProto(ctx, hook) || w.wroteHeader {
+			return
+		}
+	}
+	runProto(ctx, p.proto)
+}
+
+func (p *hookedProto) Classclone() HandlerProto {
+	return p
+}
+
+// setAppField sets the field of handler h referring to its app, that is, an
+// exported field of the app type, or an exported embedded interface field (eg.
+// yap.AppType) that the app implements. It panics if there isn't such a field.
+func setAppField(h iHandlerProto, app AppType) {
+	self := reflect.ValueOf(h).Elem()
+	vApp := reflect.ValueOf(app)
+	field := -1
+	for i, n := 0, self.NumField(); i < n; i++ {
+		f := self.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Type == vApp.Type() {
+			field = i
+			break
+		}
+		if field < 0 && f.Anonymous && f.Type.Kind() == reflect.Interface && vApp.Type().Implements(f.Type) {
+			field = i
+		}
+	}
+	if field < 0 {
+		log.Panicln("yap: handler", h.Classfname(), "has no exported field of app type", vApp.Type(),
+			"or an embedded interface it implements (eg. yap.AppType)")
+	}
+	self.Field(field).Set(vApp) // (*handler).AppV2 = app
+}
+
 // XGot_AppV2_Main is required by XGo compiler as the entry of a YAP project.
 func XGot_AppV2_Main(app AppType, handlers ...iHandlerProto) {
 	app.InitYap()
-	for _, h := range handlers {
-		reflect.ValueOf(h).Elem().Field(1).Set(reflect.ValueOf(app)) // (*handler).AppV2 = app
-		switch method, path := parseClassfname(h.Classfname()); method {
-		case "handle":
-			app.ProtoHandle(path, h)
-		default:
-			app.ProtoRoute(strings.ToUpper(method), path, h)
+	routes := make([]classRoute, len(handlers))
+	middlewares := make(map[string]iHandlerProto)
+	var errs []error
+	for i, h := range handlers {
+		route, err := parseClassfname(h.Classfname())
+		if err != nil {
+			errs = append(errs, err)
+		} else if route.middleware {
+			middlewares[route.dir] = h
 		}
+		routes[i] = route
+	}
+	if errs != nil {
+		log.Panicln(errors.Join(errs...))
+	}
+	var e *Engine
+	if ea, ok := app.(interface{ engine() *Engine }); ok {
+		e = ea.engine()
+		routeClassfiles(e, app, handlers, routes, middlewares)
+	} else {
+		routeProtos(app, handlers, routes)
 	}
 	if me, ok := app.(interface{ MainEntry() }); ok {
 		me.MainEntry()
 	} else {
-		app.Run("localhost:8080")
+		conf := serverConfig{Addr: "localhost:8080"}
+		if e != nil {
+			opts := ConfigOptions{EnvPrefix: "YAP"}
+			if err := e.loadConfig(&conf, opts); err != nil { // the config file is not for yap
+				log.Println(err, "(ignored, yap reads only addr of it)")
+				opts.File = "-"
+				e.loadConfig(&conf, opts)
+			}
+		}
+		app.Run(conf.Addr)
 	}
 }
+
+// routeClassfiles routes handlers of an app based on yap.Engine (eg. one
+// embedding yap.AppV2).
+func routeClassfiles(e *Engine, app AppType, handlers []iHandlerProto, routes []classRoute, middlewares map[string]iHandlerProto) {
+	dirs := make(map[string]*classDir)
+	var dirOf func(dir string) *classDir
+	dirOf = func(dir string) *classDir {
+		if d, ok := dirs[dir]; ok {
+			return d
+		}
+		d := &classDir{group: e.Group("")}
+		if dir != "" {
+			parent, pos := "", strings.LastIndexByte(dir, '/')
+			if pos >= 0 {
+				parent = dir[:pos]
+			}
+			*d = *dirOf(parent)
+		}
+		if mw, ok := middlewares[dir]; ok {
+			var policies []*Policy
+			if hp, ok := mw.(iHandlerPolicies); ok {
+				policies = hp.Policies()
+			}
+			d.group = d.group.Group("", policies...)
+			if hm, ok := mw.(iHandlerMiddlewares); ok {
+				d.group.Use(hm.Middlewares()...)
+			}
+			d.hooks = append(slices.Clip(d.hooks), mw)
+		}
+		dirs[dir] = d
+		return d
+	}
+	for i, h := range handlers {
+		setAppField(h, app)
+		route := routes[i]
+		if route.middleware {
+			continue
+		}
+		if route.schedule != "" {
+			opts := JobOptions{Name: route.name}
+			if opts.Name == "" {
+				opts.Name = h.Classfname()
+			}
+			if strings.ToLower(route.method) == "every" {
+				d, _ := time.ParseDuration(route.schedule)
+				e.Every(d, e.protoJob(h), opts)
+			} else {
+				e.Cron(route.schedule, e.protoJob(h), opts)
+			}
+			continue
+		}
+		d := dirOf(route.dir)
+		var r protoRouter = d.group
+		if hp, ok := h.(iHandlerPolicies); ok {
+			r = d.group.Require(hp.Policies()...)
+		}
+		var proto HandlerProto = h
+		if d.hooks != nil {
+			proto = &hookedProto{hooks: d.hooks, proto: h}
+		}
+		from := len(e.routes)
+		switch strings.ToLower(route.method) {
+		case "handle":
+			r.ProtoHandle(route.path, proto)
+		case "any":
+			for _, method := range anyMethods {
+				r.ProtoRoute(method, route.path, proto)
+			}
+		default:
+			r.ProtoRoute(strings.ToUpper(route.method), route.path, proto)
+		}
+		for j := from; j < len(e.routes); j++ {
+			e.routes[j].Name = route.name
+			e.routes[j].Classfname = h.Classfname()
+		}
+	}
+}
+
+// routeProtos routes handlers of an app which implements AppType by itself,
+// rather than being based on yap.Engine. Such an app supports routes of
+// handlers, but not _middleware handlers, scheduled jobs or policies.
+func routeProtos(app AppType, handlers []iHandlerProto, routes []classRoute) {
+	for i, h := range handlers {
+		setAppField(h, app)
+		route := routes[i]
+		if route.middleware || route.schedule != "" {
+			log.Panicln("yap:", h.Classfname(), "requires an app based on yap.AppV2")
+		}
+		if _, ok := h.(iHandlerPolicies); ok {
+			log.Panicln("yap: policies of", h.Classfname(), "require an app based on yap.AppV2")
+		}
+		switch strings.ToLower(route.method) {
+		case "handle":
+			app.ProtoHandle(route.path, h)
+		case "any":
+			for _, method := range anyMethods {
+				app.ProtoRoute(method, route.path, h)
+			}
+		default:
+			app.ProtoRoute(strings.ToUpper(route.method), route.path, h)
+		}
+	}
+}
+
+// serverConfig is the config of a YAP project without a main.yap. The listen
+// address can be set by "addr" in the config file, $YAP_ADDR or -addr. Other
+// keys of the config file are not read, and a config file which can't be parsed
+// (eg. by YAML syntax not supported) is ignored.
+type serverConfig struct {
+	Addr string `config:"addr" default:"localhost:8080"`
+}
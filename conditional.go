@@ -0,0 +1,209 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// UseETag enables automatic weak ETags of buffered responses (DATA, TEXT,
+// JSON and YAP) with 200 OK to GET and HEAD requests. A 304 Not Modified
+// response is sent if the ETag matches If-None-Match of the request. Handlers
+// which set the ETag header themselves (eg. by NotModified) are left alone.
+func (p *Engine) UseETag() {
+	p.etag = true
+}
+
+// WeakETag returns a weak ETag of data.
+func WeakETag(data []byte) string {
+	h := fnv.New64a()
+	h.Write(data)
+	return `W/"` + strconv.FormatUint(h.Sum64(), 36) + `"`
+}
+
+// wantETag reports whether an automatic ETag is wanted for a response with
+// the status code. See UseETag.
+func (p *Context) wantETag(code int) bool {
+	return p.engine.etag && code == http.StatusOK && isGetOrHead(p.Method) &&
+		p.ResponseWriter.Header().Get("ETag") == ""
+}
+
+func isGetOrHead(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// -----------------------------------------------------------------------------
+
+// NotModified sets the ETag and Last-Modified headers (if etag isn't empty and
+// modtime isn't zero), and checks If-None-Match and If-Modified-Since of a GET
+// or HEAD request. If the resource isn't modified, it sends a 304 response
+// and returns true, eg.
+//
+//	if ctx.NotModified(article.ETag(), article.Updated) {
+//		return
+//	}
+//	ctx.JSON(200, article)
+func (p *Context) NotModified(etag string, modtime time.Time) bool {
+	h := p.ResponseWriter.Header()
+	if etag != "" {
+		h.Set("ETag", etag)
+	}
+	if !isZeroTime(modtime) {
+		h.Set("Last-Modified", modtime.UTC().Format(http.TimeFormat))
+	}
+	if !isGetOrHead(p.Method) {
+		return false
+	}
+	if inm := p.Request.Header.Get("If-None-Match"); inm != "" {
+		if etag == "" || !matchETag(inm, etag, weakMatch) {
+			return false
+		}
+	} else if ims := p.Request.Header.Get("If-Modified-Since"); ims == "" || isZeroTime(modtime) {
+		return false
+	} else if t, err := http.ParseTime(ims); err != nil || modtime.Truncate(time.Second).After(t) {
+		return false
+	}
+	delete(h, "Content-Type")
+	delete(h, "Content-Length")
+	p.ResponseWriter.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// CheckPreconditions checks If-Match, If-Unmodified-Since (and If-None-Match of
+// unsafe methods) of the request against the current ETag (empty if the
+// resource doesn't exist) and modification time of the resource, which lets
+// updates be done with optimistic concurrency. If a precondition fails, it
+// sends a 412 Precondition Failed response and returns false, eg.
+//
+//	if !ctx.CheckPreconditions(article.ETag(), article.Updated) {
+//		return
+//	}
+//	// update the article
+func (p *Context) CheckPreconditions(etag string, modtime time.Time) bool {
+	req := p.Request.Header
+	if im := req.Get("If-Match"); im != "" {
+		if etag == "" || !matchETag(im, etag, strongMatch) {
+			return p.preconditionFailed()
+		}
+	} else if ius := req.Get("If-Unmodified-Since"); ius != "" && !isZeroTime(modtime) {
+		if t, err := http.ParseTime(ius); err == nil && modtime.Truncate(time.Second).After(t) {
+			return p.preconditionFailed()
+		}
+	}
+	if inm := req.Get("If-None-Match"); inm != "" && !isGetOrHead(p.Method) {
+		if etag != "" && matchETag(inm, etag, weakMatch) { // eg. "If-None-Match: *" to create only
+			return p.preconditionFailed()
+		}
+	}
+	return true
+}
+
+func (p *Context) preconditionFailed() bool {
+	code := http.StatusPreconditionFailed
+	http.Error(p.ResponseWriter, http.StatusText(code), code)
+	return false
+}
+
+func isZeroTime(t time.Time) bool {
+	return t.IsZero() || t.Equal(time.Unix(0, 0))
+}
+
+func weakMatch(a, b string) bool {
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
+
+func strongMatch(a, b string) bool {
+	return a == b && !strings.HasPrefix(a, "W/")
+}
+
+// matchETag reports whether etag matches one in the list of an If-Match or
+// If-None-Match header.
+func matchETag(list, etag string, match func(a, b string) bool) bool {
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "*" || match(item, etag) {
+			return true
+		}
+	}
+	return false
+}
+
+// -----------------------------------------------------------------------------
+
+// CacheControl represents directives of the Cache-Control header. A duration
+// directive is omitted if it is 0, and is 0 if it is negative, eg. MaxAge: -1
+// means "max-age=0".
+type CacheControl struct {
+	MaxAge               time.Duration // max-age
+	SMaxAge              time.Duration // s-maxage, for shared caches
+	StaleWhileRevalidate time.Duration // stale-while-revalidate
+	StaleIfError         time.Duration // stale-if-error
+
+	Public         bool // public
+	Private        bool // private
+	NoCache        bool // no-cache
+	NoStore        bool // no-store
+	MustRevalidate bool // must-revalidate
+	Immutable      bool // immutable
+}
+
+// String returns the value of the Cache-Control header.
+func (c CacheControl) String() string {
+	var parts []string
+	flag := func(on bool, name string) {
+		if on {
+			parts = append(parts, name)
+		}
+	}
+	flag(c.Public, "public")
+	flag(c.Private, "private")
+	flag(c.NoCache, "no-cache")
+	flag(c.NoStore, "no-store")
+	seconds := func(d time.Duration, name string) {
+		if d != 0 {
+			parts = append(parts, name+"="+strconv.FormatInt(int64(max(d, 0)/time.Second), 10))
+		}
+	}
+	seconds(c.MaxAge, "max-age")
+	seconds(c.SMaxAge, "s-maxage")
+	seconds(c.StaleWhileRevalidate, "stale-while-revalidate")
+	seconds(c.StaleIfError, "stale-if-error")
+	flag(c.MustRevalidate, "must-revalidate")
+	flag(c.Immutable, "immutable")
+	return strings.Join(parts, ", ")
+}
+
+// SetCacheControl sets the Cache-Control header of the response, eg.
+//
+//	ctx.SetCacheControl(yap.CacheControl{Public: true, MaxAge: time.Hour})
+func (p *Context) SetCacheControl(cc CacheControl) {
+	p.ResponseWriter.Header().Set("Cache-Control", cc.String())
+}
+
+// NoStore sets "Cache-Control: no-store" to prevent the response from being
+// cached.
+func (p *Context) NoStore() {
+	p.SetCacheControl(CacheControl{NoStore: true})
+}
+
+// -----------------------------------------------------------------------------
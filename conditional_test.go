@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/goplus/yap"
+)
+
+func serveWith(e http.Handler, method, path string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for i := 0; i < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestAutoETag(t *testing.T) {
+	e := yap.New(fstest.MapFS{
+		"page_yap.html": {Data: []byte(`<p>{{.}}</p>`)},
+	})
+	e.UseETag()
+	e.GET("/json", func(ctx *yap.Context) {
+		ctx.JSON(200, yap.H{"id": 1})
+	})
+	e.GET("/page", func(ctx *yap.Context) {
+		ctx.YAP(200, "page", "hi")
+	})
+	e.GET("/err", func(ctx *yap.Context) {
+		ctx.JSON(500, yap.H{"id": 1})
+	})
+
+	for _, path := range []string{"/json", "/page"} {
+		w := serveWith(e, "GET", path)
+		etag := w.Header().Get("ETag")
+		if w.Code != 200 || len(etag) < 4 || etag[:3] != `W/"` {
+			t.Fatal(path, w.Code, etag)
+		}
+		w = serveWith(e, "GET", path, "If-None-Match", `"x", `+etag)
+		if w.Code != 304 || w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" {
+			t.Fatal(path, "If-None-Match:", w.Code, w.Body.String())
+		}
+	}
+	if w := serveWith(e, "GET", "/err"); w.Header().Get("ETag") != "" {
+		t.Fatal("ETag of 500:", w.Header().Get("ETag"))
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	for _, c := range []struct {
+		cc   yap.CacheControl
+		want string
+	}{
+		{yap.CacheControl{}, ""},
+		{yap.CacheControl{Public: true, MaxAge: -1, SMaxAge: time.Hour}, "public, max-age=0, s-maxage=3600"},
+		{yap.CacheControl{MaxAge: time.Minute, StaleWhileRevalidate: -1}, "max-age=60, stale-while-revalidate=0"},
+	} {
+		if got := c.cc.String(); got != c.want {
+			t.Fatal("CacheControl:", got)
+		}
+	}
+}
+
+func TestConditional(t *testing.T) {
+	const etag = `"v2"`
+	updated := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	e := yap.New()
+	e.GET("/article", func(ctx *yap.Context) {
+		if ctx.NotModified(etag, updated) {
+			return
+		}
+		ctx.SetCacheControl(yap.CacheControl{Private: true, MaxAge: time.Minute, MustRevalidate: true})
+		ctx.TEXT(200, "text/plain", "article")
+	})
+	e.PUT("/article", func(ctx *yap.Context) {
+		if ctx.CheckPreconditions(etag, updated) {
+			ctx.TEXT(200, "text/plain", "updated")
+		}
+	})
+
+	w := serveWith(e, "GET", "/article")
+	if w.Code != 200 || w.Header().Get("ETag") != etag ||
+		w.Header().Get("Last-Modified") != "Fri, 02 Jan 2026 03:04:05 GMT" ||
+		w.Header().Get("Cache-Control") != "private, max-age=60, must-revalidate" {
+		t.Fatal("GET:", w.Code, w.Header())
+	}
+	for _, c := range []struct {
+		method, header, value string
+		code                  int
+	}{
+		{"GET", "If-None-Match", `W/"v2"`, 304},
+		{"GET", "If-None-Match", `"v1"`, 200},
+		{"GET", "If-Modified-Since", "Fri, 02 Jan 2026 03:04:05 GMT", 304},
+		{"GET", "If-Modified-Since", "Fri, 02 Jan 2026 03:04:04 GMT", 200},
+		{"PUT", "If-Match", `"v2"`, 200},
+		{"PUT", "If-Match", `"v1"`, 412},
+		{"PUT", "If-Match", `W/"v2"`, 412},
+		{"PUT", "If-Unmodified-Since", "Fri, 02 Jan 2026 03:04:04 GMT", 412},
+		{"PUT", "If-None-Match", "*", 412},
+		{"PUT", "", "", 200},
+	} {
+		var header []string
+		if c.header != "" {
+			header = []string{c.header, c.value}
+		}
+		if w := serveWith(e, c.method, "/article", header...); w.Code != c.code {
+			t.Fatal(c.method, c.header, c.value, "=>", w.Code)
+		}
+	}
+}
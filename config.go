@@ -0,0 +1,418 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goplus/yap/internal/conf"
+)
+
+// -----------------------------------------------------------------------------
+
+// ConfigOptions represents options of LoadConfig.
+type ConfigOptions struct {
+	// File is the config file in $YapFS, whose format is decided by its
+	// extension (.json, .yaml, .yml or .toml). By default, the first existing
+	// one of config.json, config.yaml, config.yml and config.toml is loaded.
+	// "-" means no config file.
+	File string
+
+	// EnvPrefix is the prefix of environment variables, eg. "APP" binds db.url
+	// to APP_DB_URL. Environment variables aren't read if it is empty.
+	EnvPrefix string
+
+	// Args are command-line arguments (default is os.Args[1:]), eg.
+	// "-db.url=..." or "--db.url ...". Unknown flags are ignored.
+	Args []string
+
+	// LookupEnv looks up environment variables (default is os.LookupEnv).
+	LookupEnv func(key string) (string, bool)
+}
+
+var configFiles = []string{"config.json", "config.yaml", "config.yml", "config.toml"}
+
+// LoadConfig binds conf (a pointer to struct) from sources in order of
+// precedence (a later one overrides earlier ones):
+//  1. defaults: values of conf before loading, or `default:"..."` tags of
+//     fields with zero values.
+//  2. the config file (see ConfigOptions.File).
+//  3. environment variables with ConfigOptions.EnvPrefix.
+//  4. command-line flags.
+//
+// A field is bound to its key declared by the `config:"key"` tag, or its
+// lowercase name by default. Fields of nested structs are bound to dotted
+// keys, eg. db.url. Keys in the config file are matched case-insensitively,
+// ignoring "_" and "-". Tag options "required" and "secret" declare required
+// fields and fields redacted by DumpConfig, eg.
+//
+//	type Config struct {
+//		Addr string `config:"addr" default:"localhost:8080"`
+//		DB   struct {
+//			URL string `config:"url,required,secret"`
+//		} `config:"db"`
+//	}
+//
+// conf is provided to handlers (see Provide), and is returned by Config.
+func (p *Engine) LoadConfig(conf any, opts ...ConfigOptions) error {
+	if err := p.loadConfig(conf, opts...); err != nil {
+		return err
+	}
+	p.conf = conf
+	p.Provide(conf)
+	return nil
+}
+
+// Config returns the config loaded by LoadConfig.
+func (p *Engine) Config() any {
+	return p.conf
+}
+
+// Config returns the config loaded by Engine.LoadConfig, eg. in a handler:
+//
+//	cfg := config.(*Config)
+func (p *Context) Config() any {
+	return p.engine.conf
+}
+
+func (p *Engine) loadConfig(conf any, opts ...ConfigOptions) error {
+	var opt ConfigOptions
+	if opts != nil {
+		opt = opts[0]
+	}
+	if opt.Args == nil {
+		opt.Args = os.Args[1:]
+	}
+	if opt.LookupEnv == nil {
+		opt.LookupEnv = os.LookupEnv
+	}
+	v := reflect.ValueOf(conf)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		log.Panicln("LoadConfig: conf must be a pointer to struct")
+	}
+	tree, src, err := p.readConfigFile(opt.File)
+	if err != nil {
+		return err
+	}
+	fields := configFields(v.Elem(), nil, nil)
+	bools := make(map[string]bool)
+	for _, f := range fields {
+		if f.v.Kind() == reflect.Bool {
+			bools[normConfigKey(f.key)] = true
+		}
+	}
+	flags := parseConfigFlags(opt.Args, bools)
+	var errs []error
+	for _, f := range fields {
+		if f.def != nil && f.v.IsZero() {
+			if err = setConfig(f.v, *f.def); err != nil {
+				errs = append(errs, fmt.Errorf("config: %s (from default): %w", f.key, err))
+			}
+		}
+		if val, ok := lookupConfig(tree, f.path); ok && val != nil {
+			if err = setConfig(f.v, val); err != nil {
+				errs = append(errs, fmt.Errorf("config: %s (from %s): %w", f.key, src, err))
+			}
+		}
+		if opt.EnvPrefix != "" {
+			name := opt.EnvPrefix + "_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(f.key))
+			if val, ok := opt.LookupEnv(name); ok {
+				if err = setConfig(f.v, val); err != nil {
+					errs = append(errs, fmt.Errorf("config: %s (from $%s): %w", f.key, name, err))
+				}
+			}
+		}
+		if val, ok := flags[normConfigKey(f.key)]; ok {
+			if val == "" && f.v.Kind() == reflect.Bool {
+				val = "true"
+			}
+			if err = setConfig(f.v, val); err != nil {
+				errs = append(errs, fmt.Errorf("config: %s (from flag -%s): %w", f.key, f.key, err))
+			}
+		}
+		if f.required && f.v.IsZero() {
+			errs = append(errs, fmt.Errorf("config: %s is required", f.key))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *Engine) readConfigFile(name string) (tree map[string]any, src string, err error) {
+	if name == "-" {
+		return
+	}
+	fsys := p.yapFS()
+	var data []byte
+	if name != "" {
+		data, err = fs.ReadFile(fsys, name)
+	} else {
+		for _, name = range configFiles {
+			if data, err = fs.ReadFile(fsys, name); !errors.Is(err, fs.ErrNotExist) {
+				break
+			}
+		}
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, "", nil
+		}
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("config: %w", err)
+	}
+	switch ext := path.Ext(name); ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&tree)
+	case ".yaml", ".yml":
+		tree, err = conf.ParseYAML(data)
+	case ".toml":
+		tree, err = conf.ParseTOML(data)
+	default:
+		err = errors.New("unknown format " + ext)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("config: %s: %w", name, err)
+	}
+	return tree, name, nil
+}
+
+// configField represents a field bound to a config key.
+type configField struct {
+	key      string   // eg. "db.url"
+	path     []string // eg. ["db", "url"]
+	v        reflect.Value
+	def      *string // `default:"..."` tag
+	required bool
+	secret   bool
+}
+
+var tyDuration = reflect.TypeOf(time.Duration(0))
+
+func configFields(v reflect.Value, path []string, fields []configField) []configField {
+	t := v.Type()
+	for i, n := 0, t.NumField(); i < n; i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("config")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			sub := path
+			if name != "" || !f.Anonymous {
+				if name == "" {
+					name = strings.ToLower(f.Name)
+				}
+				sub = append(path[:len(path):len(path)], name)
+			}
+			fields = configFields(fv, sub, fields)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		cf := configField{path: append(path[:len(path):len(path)], name), v: fv}
+		cf.key = strings.Join(cf.path, ".")
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "required":
+				cf.required = true
+			case "secret":
+				cf.secret = true
+			}
+		}
+		if def, ok := f.Tag.Lookup("default"); ok {
+			cf.def = &def
+		}
+		fields = append(fields, cf)
+	}
+	return fields
+}
+
+// normConfigKey normalizes a key by removing "_" and "-" and lowercasing it.
+func normConfigKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+func lookupConfig(tree map[string]any, path []string) (any, bool) {
+	var cur any = tree
+	for _, name := range path {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		cur, ok = m[name]
+		if !ok {
+			norm := normConfigKey(name)
+			for k, v := range m {
+				if normConfigKey(k) == norm {
+					cur, ok = v, true
+					break
+				}
+			}
+			if !ok {
+				return nil, false
+			}
+		}
+	}
+	return cur, true
+}
+
+// parseConfigFlags parses flags like -key=value, --key value or -key (for
+// booleans), and returns values by normalized keys. A flag of bools (by
+// normalized keys) never takes the next argument as its value, eg. "-debug
+// serve".
+func parseConfigFlags(args []string, bools map[string]bool) map[string]string {
+	ret := make(map[string]string)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			continue
+		}
+		name := strings.TrimPrefix(arg[1:], "-")
+		if k, v, ok := strings.Cut(name, "="); ok {
+			ret[normConfigKey(k)] = v
+		} else if key := normConfigKey(name); !bools[key] && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			ret[key] = args[i+1]
+			i++
+		} else {
+			ret[key] = ""
+		}
+	}
+	return ret
+}
+
+// setConfig sets v by val, which is a string, []any, or a scalar decoded from
+// JSON.
+func setConfig(v reflect.Value, val any) error {
+	switch val := val.(type) {
+	case []any:
+		if v.Kind() != reflect.Slice {
+			return errors.New("a single value is expected")
+		}
+		s := reflect.MakeSlice(v.Type(), len(val), len(val))
+		for i, item := range val {
+			if err := setConfig(s.Index(i), item); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	case map[string]any:
+		return errors.New("a value is expected")
+	case string:
+		return setConfigString(v, val)
+	}
+	return setConfigString(v, fmt.Sprint(val))
+}
+
+func setConfigString(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+		return nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("bad bool %q", s)
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == tyDuration {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return fmt.Errorf("bad duration %q", s)
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("bad integer %q", s)
+		}
+		v.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("bad unsigned integer %q", s)
+		}
+		v.SetUint(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("bad number %q", s)
+		}
+		v.SetFloat(f)
+		return nil
+	case reflect.Slice:
+		items := strings.Split(s, ",")
+		vals := make([]any, len(items))
+		for i, item := range items {
+			vals[i] = strings.TrimSpace(item)
+		}
+		return setConfig(v, vals)
+	}
+	return fmt.Errorf("unsupported type %v", v.Type())
+}
+
+// DumpConfig writes conf (a pointer to struct bound by LoadConfig) as lines
+// of "key = value", with values of secret fields redacted, eg.
+//
+//	addr = "localhost:8080"
+//	db.url = "******"
+func DumpConfig(w io.Writer, conf any) error {
+	v := reflect.ValueOf(conf)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		log.Panicln("DumpConfig: conf must be a pointer to struct")
+	}
+	for _, f := range configFields(v.Elem(), nil, nil) {
+		fv, val := f.v, ""
+		switch {
+		case f.secret && !fv.IsZero():
+			val = `"******"`
+		case fv.Kind() == reflect.String:
+			val = strconv.Quote(fv.String())
+		default:
+			val = fmt.Sprint(fv.Interface())
+		}
+		if _, err := fmt.Fprintf(w, "%s = %s\n", f.key, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,206 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/goplus/yap"
+)
+
+type dbConfig struct {
+	URL      string   `config:"url,required,secret"`
+	MaxConns int      `config:"max_conns" default:"4"`
+	Hosts    []string `config:"hosts"`
+}
+
+type siteConfig struct {
+	Addr    string        `config:"addr" default:"localhost:8080"`
+	Debug   bool          `config:"debug"`
+	Timeout time.Duration `config:"timeout" default:"5s"`
+	Title   string
+	DB      dbConfig `config:"db"`
+	Skipped string   `config:"-"`
+}
+
+var configFS = fstest.MapFS{
+	"config.yaml": {Data: []byte(`
+addr: ":9000"
+title: Blog
+db:
+  url: mysql://root@/blog
+  maxConns: 8
+  hosts: [a.local, b.local]
+`)},
+	"site.toml": {Data: []byte(`
+title = "Site"
+[db]
+url = "sqlite://site.db"
+`)},
+	"site.json": {Data: []byte(`{"debug": true, "db": {"max_conns": 16}}`)},
+	"bad.yaml":  {Data: []byte("db:\n  url: [a, b]")},
+}
+
+func noEnv(key string) (string, bool) {
+	return "", false
+}
+
+func TestLoadConfig(t *testing.T) {
+	e := yap.New(configFS)
+	var conf siteConfig
+	err := e.LoadConfig(&conf, yap.ConfigOptions{
+		EnvPrefix: "APP",
+		Args:      []string{"-debug", "serve", "--db.max-conns", "32", "-test.v=true"},
+		LookupEnv: func(key string) (string, bool) {
+			switch key {
+			case "APP_TIMEOUT":
+				return "1m", true
+			case "APP_DB_MAX_CONNS":
+				return "12", true
+			}
+			return "", false
+		},
+	})
+	if err != nil {
+		t.Fatal("LoadConfig:", err)
+	}
+	if conf.Addr != ":9000" || !conf.Debug || conf.Timeout != time.Minute || conf.Title != "Blog" ||
+		conf.DB.URL != "mysql://root@/blog" || conf.DB.MaxConns != 32 || strings.Join(conf.DB.Hosts, ",") != "a.local,b.local" {
+		t.Fatalf("LoadConfig: %+v", conf)
+	}
+	if e.Config() != &conf {
+		t.Fatal("Config:", e.Config())
+	}
+
+	var b strings.Builder
+	if err = yap.DumpConfig(&b, &conf); err != nil {
+		t.Fatal(err)
+	}
+	const dump = `addr = ":9000"
+debug = true
+timeout = 1m0s
+title = "Blog"
+db.url = "******"
+db.max_conns = 32
+db.hosts = [a.local b.local]
+`
+	if b.String() != dump {
+		t.Fatal("DumpConfig:", b.String())
+	}
+
+	e.GET("/", func(ctx *yap.Context) {
+		ctx.Text__2(ctx.Config().(*siteConfig).Title)
+	})
+	e.ProtoRoute("GET", "/site", &configHandler{})
+	if w := serveWith(e, "GET", "/"); w.Body.String() != "Blog" {
+		t.Fatal("ctx.Config:", w.Body.String())
+	}
+	if w := serveWith(e, "GET", "/site"); w.Body.String() != "Blog" {
+		t.Fatal("inject:", w.Body.String())
+	}
+}
+
+type configHandler struct {
+	yap.Handler
+	Conf *siteConfig `yap:"inject"`
+}
+
+func (p *configHandler) Main(ctx *yap.Context) {
+	ctx.Text__2(p.Conf.Title)
+}
+
+func (p *configHandler) Classclone() yap.HandlerProto {
+	ret := *p
+	return &ret
+}
+
+func TestLoadConfigFiles(t *testing.T) {
+	e := yap.New(configFS)
+	var conf siteConfig
+	if err := e.LoadConfig(&conf, yap.ConfigOptions{File: "site.toml", Args: []string{}, LookupEnv: noEnv}); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Title != "Site" || conf.DB.URL != "sqlite://site.db" || conf.DB.MaxConns != 4 || conf.Addr != "localhost:8080" {
+		t.Fatalf("site.toml: %+v", conf)
+	}
+	conf = siteConfig{DB: dbConfig{URL: "x"}}
+	if err := e.LoadConfig(&conf, yap.ConfigOptions{File: "site.json", Args: []string{}, LookupEnv: noEnv}); err != nil {
+		t.Fatal(err)
+	}
+	if !conf.Debug || conf.DB.MaxConns != 16 || conf.DB.URL != "x" {
+		t.Fatalf("site.json: %+v", conf)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	e := yap.New(configFS)
+	var conf siteConfig
+	err := e.LoadConfig(&conf, yap.ConfigOptions{File: "-", Args: []string{"-timeout", "soon"}, LookupEnv: noEnv})
+	if err == nil || !strings.Contains(err.Error(), "config: db.url is required") ||
+		!strings.Contains(err.Error(), `config: timeout (from flag -timeout): bad duration "soon"`) {
+		t.Fatal("LoadConfig:", err)
+	}
+	err = e.LoadConfig(&conf, yap.ConfigOptions{File: "bad.yaml", Args: []string{}, LookupEnv: noEnv})
+	if err == nil || !strings.Contains(err.Error(), "config: db.url (from bad.yaml): a single value is expected") {
+		t.Fatal("LoadConfig:", err)
+	}
+	if err = e.LoadConfig(&conf, yap.ConfigOptions{File: "none.yaml"}); err == nil {
+		t.Fatal("LoadConfig: no error")
+	}
+}
+
+type AddrAppV2 struct {
+	yap.AppV2
+}
+
+func (p *AddrAppV2) Main() {
+	yap.XGot_AppV2_Main(p, &grammarHandler{fname: "get"})
+}
+
+func TestAppV2Addr(t *testing.T) {
+	addrOf := func(fsys fstest.MapFS) string {
+		app := new(AddrAppV2)
+		app.InitYap(fsys)
+		var addr string
+		app.SetLAS(func(a string, h http.Handler) error {
+			addr = a
+			return nil
+		})
+		app.Main()
+		return addr
+	}
+	// only addr is read from the config file
+	if addr := addrOf(fstest.MapFS{
+		"config.json": {Data: []byte(`{"addr": ":7000", "debug": "maybe", "db": {"url": 1}}`)},
+	}); addr != ":7000" {
+		t.Fatal("addr:", addr)
+	}
+	t.Setenv("YAP_ADDR", ":9999")
+	if addr := addrOf(fstest.MapFS{}); addr != ":9999" {
+		t.Fatal("addr:", addr)
+	}
+	// a config file yap can't parse is ignored
+	if addr := addrOf(fstest.MapFS{
+		"config.yaml": {Data: []byte("defaults: &defaults\n  adapter: postgres\ndb: {url: x}\n")},
+	}); addr != ":9999" {
+		t.Fatal("addr:", addr)
+	}
+}
@@ -17,12 +17,17 @@
 package yap
 
 import (
+	"bytes"
 	"encoding/json"
+	"html/template"
 	"io"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/goplus/yap/trace"
 )
 
 // Context is the context of a request, it is passed to the handler function.
@@ -31,11 +36,30 @@ type Context struct {
 	http.ResponseWriter
 
 	engine *Engine
+	sess   *sessionRef       // shared by copies, see sessionRef
+	params map[string]string // path params, see UnderlyingSetPathParam
+
+	uploads *uploadState // see MultipartForm
 }
 
+// UnderlyingSetPathParam sets a path param captured by the route. It doesn't
+// read the request body (eg. for Proxy to forward it): path params are kept
+// apart from Request.Form until the form is parsed by Param or MultipartForm.
 func (p *Context) UnderlyingSetPathParam(name, val string) {
-	p.ParseForm()
-	p.Form.Set(name, val)
+	if p.params == nil {
+		p.params = make(map[string]string)
+	}
+	p.params[name] = val
+}
+
+// setFormParams sets path params to the parsed Request.Form, where they take
+// precedence over values of the url query and the request body.
+func (p *Context) setFormParams() {
+	if p.Form != nil {
+		for name, val := range p.params {
+			p.Form.Set(name, val)
+		}
+	}
 }
 
 // XGo_Env returns the value associated with the name.
@@ -46,7 +70,19 @@ func (p *Context) XGo_Env(name string) string {
 
 // Param returns the value associated with the name.
 // If the name exists in URL query, it returns the first value for the name.
+// If the request body is beyond BodyLimit, the request is replied with a 413
+// error, and the response of the handler is dropped.
 func (p *Context) Param(name string) string {
+	if val, ok := p.params[name]; ok {
+		return val
+	}
+	var err error
+	if isMultipart(p.Request) {
+		_, err = p.MultipartForm()
+	} else {
+		err = p.parseForm()
+	}
+	p.replyTooLarge(err)
 	return p.FormValue(name)
 }
 
@@ -105,10 +141,13 @@ func (p *Context) Redirect(url string, code ...int) {
 	if code != nil {
 		statusCode = code[0]
 	}
-	http.Redirect(p.ResponseWriter, p.Request, url, statusCode)
+	p.engine.redirect(p.ResponseWriter, p.Request, url, statusCode)
 }
 
 func (p *Context) TEXT(code int, mime string, text string) {
+	if p.wantETag(code) && p.NotModified(WeakETag([]byte(text)), time.Time{}) {
+		return
+	}
 	w := p.ResponseWriter
 	h := w.Header()
 	h.Set("Content-Length", strconv.Itoa(len(text)))
@@ -118,6 +157,9 @@ func (p *Context) TEXT(code int, mime string, text string) {
 }
 
 func (p *Context) DATA(code int, mime string, data []byte) {
+	if p.wantETag(code) && p.NotModified(WeakETag(data), time.Time{}) {
+		return
+	}
 	w := p.ResponseWriter
 	h := w.Header()
 	h.Set("Content-Length", strconv.Itoa(len(data)))
@@ -144,18 +186,79 @@ func (p *Context) JSON(code int, data any) {
 
 func (p *Context) YAP(code int, yapFile string, data any) {
 	w := p.ResponseWriter
-	t := p.engine.templ(yapFile)
+	t, release := p.templ(yapFile)
 	if t == nil {
 		log.Panicln("YAP: not find template:", yapFile)
 	}
+	defer release()
 	h := w.Header()
 	h.Set("Content-Type", "text/html")
-	err := t.Execute(w, data)
-	if err != nil {
+	if m := p.engine.metrics; m != nil {
+		defer m.render.Since(time.Now(), yapFile)
+	}
+	if trace.Enabled() {
+		_, span := trace.Start(p.Request.Context(), "yap "+yapFile, trace.KindInternal)
+		defer span.End()
+	}
+	if p.wantETag(code) { // render into a buffer to compute the ETag
+		var b bytes.Buffer
+		if err := t.Execute(&b, data); err != nil {
+			log.Panicln("YAP:", err)
+		}
+		p.DATA(code, "text/html", b.Bytes())
+		return
+	}
+	err := t.Execute(respWriter{p}, data)
+	if err != nil && p.Err() == nil { // not cancelled by the client or Timeout
 		log.Panicln("YAP:", err)
 	}
 }
 
+// respWriter writes to the current ResponseWriter of a context, which may be
+// replaced while a template is executing (eg. a session is loaded).
+type respWriter struct {
+	ctx *Context
+}
+
+func (w respWriter) Write(b []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil { // stop rendering if the request is cancelled
+		return 0, err
+	}
+	return w.ctx.ResponseWriter.Write(b)
+}
+
+// templ returns the template named yapFile, and release to be called after
+// it is executed. Templates are executed in clones of the template set taken
+// from a pool, so a clone (and its escaping by html/template) is reused by
+// following requests. Template functions bound to the request context are
+// bound to this request until release.
+func (p *Context) templ(yapFile string) (t *template.Template, release func()) {
+	e := p.engine
+	if e.templ(yapFile) == nil {
+		return nil, nil
+	}
+	root, _ := e.tplPool.Get().(*template.Template)
+	if root == nil {
+		var err error
+		if root, err = e.tpl.Clone(); err != nil { // e.tpl is never executed
+			log.Panicln("YAP:", err)
+		}
+	}
+	if len(e.ctxFuncs) > 0 {
+		root.Funcs(e.bindFuncs(p))
+	}
+	return root.Lookup(yapFile), func() {
+		if len(e.ctxFuncs) > 0 {
+			root.Funcs(e.bindFuncs(nil)) // don't keep the request alive in the pool
+		}
+		e.tplPool.Put(root)
+	}
+}
+
+// STREAM replies to the request by copying from read. Range requests aren't
+// supported (see ServeContent and Attachment for them).
+//
+// If buf is small (less than 32KB), the response is flushed after each write.
 func (p *Context) STREAM(code int, mime string, read io.Reader, buf []byte) {
 	w := p.ResponseWriter
 	h := w.Header()
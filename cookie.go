@@ -0,0 +1,248 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrInvalidCookie is returned when a signed or encrypted cookie can't be
+	// verified by any of the keys, or it is expired.
+	ErrInvalidCookie = errors.New("yap: invalid cookie")
+)
+
+// -----------------------------------------------------------------------------
+
+type cookieKey struct {
+	hash  []byte
+	block cipher.AEAD
+}
+
+// CookieCodec signs or encrypts cookie values. It supports key rotation: the
+// first key is used to encode values, and all keys are tried in order to
+// decode them, so old keys can be retired gracefully.
+type CookieCodec struct {
+	keys []cookieKey
+	now  func() time.Time
+}
+
+// NewCookieCodec creates a CookieCodec by specified secret keys. The first key
+// is the current one. A key should be at least 32 bytes of random data.
+func NewCookieCodec(keys ...[]byte) *CookieCodec {
+	if len(keys) == 0 {
+		log.Panicln("NewCookieCodec: no keys")
+	}
+	ret := &CookieCodec{keys: make([]cookieKey, len(keys)), now: time.Now}
+	for i, key := range keys {
+		ret.keys[i] = newCookieKey(key)
+	}
+	return ret
+}
+
+func newCookieKey(key []byte) cookieKey {
+	hash := deriveKey(key, "yap cookie sign")
+	block, err := aes.NewCipher(deriveKey(key, "yap cookie encrypt"))
+	if err != nil {
+		log.Panicln("NewCookieCodec:", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		log.Panicln("NewCookieCodec:", err)
+	}
+	return cookieKey{hash: hash, block: aead}
+}
+
+func deriveKey(key []byte, label string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(label))
+	return h.Sum(nil)
+}
+
+// Sign returns a signed form of value. The signature is bound to the cookie
+// name and the signing time.
+//
+//	base64(timestamp | value | hmac-sha256(name | timestamp | value))
+func (p *CookieCodec) Sign(name string, value []byte) string {
+	b := make([]byte, 8, 8+len(value)+sha256.Size)
+	binary.BigEndian.PutUint64(b, uint64(p.now().Unix()))
+	b = append(b, value...)
+	b = append(b, p.keys[0].mac(name, b)...)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Verify verifies a signed value generated by Sign and returns the original
+// value. If maxAge > 0, values signed more than maxAge ago are rejected.
+func (p *CookieCodec) Verify(name, signed string, maxAge time.Duration) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(signed)
+	if err != nil || len(b) < 8+sha256.Size {
+		return nil, ErrInvalidCookie
+	}
+	data, sig := b[:len(b)-sha256.Size], b[len(b)-sha256.Size:]
+	for _, key := range p.keys {
+		if hmac.Equal(key.mac(name, data), sig) {
+			if !p.checkAge(data, maxAge) {
+				return nil, ErrInvalidCookie
+			}
+			return data[8:], nil
+		}
+	}
+	return nil, ErrInvalidCookie
+}
+
+// Encrypt returns an encrypted form of value (AES-256-GCM). The cookie name
+// and the encrypting time are authenticated too.
+//
+//	base64(timestamp | nonce | aes-gcm(value, name | timestamp))
+func (p *CookieCodec) Encrypt(name string, value []byte) string {
+	aead := p.keys[0].block
+	n := aead.NonceSize()
+	b := make([]byte, 8+n, 8+n+len(value)+aead.Overhead())
+	binary.BigEndian.PutUint64(b, uint64(p.now().Unix()))
+	if _, err := rand.Read(b[8:]); err != nil {
+		log.Panicln("CookieCodec.Encrypt:", err)
+	}
+	b = aead.Seal(b, b[8:], value, additionalData(name, b[:8]))
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decrypt decrypts a value generated by Encrypt and returns the original
+// value. If maxAge > 0, values encrypted more than maxAge ago are rejected.
+func (p *CookieCodec) Decrypt(name, encrypted string, maxAge time.Duration) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(encrypted)
+	if err != nil || len(b) < 8 {
+		return nil, ErrInvalidCookie
+	}
+	ad := additionalData(name, b[:8])
+	for _, key := range p.keys {
+		aead := key.block
+		n := aead.NonceSize()
+		if len(b) < 8+n {
+			break
+		}
+		if value, err := aead.Open(nil, b[8:8+n], b[8+n:], ad); err == nil {
+			if !p.checkAge(b, maxAge) {
+				return nil, ErrInvalidCookie
+			}
+			return value, nil
+		}
+	}
+	return nil, ErrInvalidCookie
+}
+
+func (p *CookieCodec) checkAge(b []byte, maxAge time.Duration) bool {
+	if maxAge > 0 {
+		t := time.Unix(int64(binary.BigEndian.Uint64(b)), 0)
+		return p.now().Sub(t) <= maxAge
+	}
+	return true
+}
+
+func (p cookieKey) mac(name string, data []byte) []byte {
+	h := hmac.New(sha256.New, p.hash)
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func additionalData(name string, ts []byte) []byte {
+	ad := make([]byte, 0, len(name)+1+len(ts))
+	ad = append(ad, name...)
+	ad = append(ad, 0)
+	return append(ad, ts...)
+}
+
+// -----------------------------------------------------------------------------
+
+// SetCookieKeys sets secret keys used by signed/encrypted cookies and the
+// cookie session store. The first key is the current one, and the others are
+// old keys still accepted when decoding (key rotation).
+func (p *Engine) SetCookieKeys(keys ...[]byte) {
+	p.cookie = NewCookieCodec(keys...)
+}
+
+// CookieCodec returns the cookie codec set by SetCookieKeys.
+func (p *Engine) CookieCodec() *CookieCodec {
+	if p.cookie == nil {
+		log.Panicln("yap: cookie keys not set, please call SetCookieKeys first")
+	}
+	return p.cookie
+}
+
+// SetCookie adds a Set-Cookie header to the response.
+func (p *Context) SetCookie(c *http.Cookie) {
+	http.SetCookie(p.ResponseWriter, c)
+}
+
+// SetSignedCookie adds a Set-Cookie header whose value is c.Value signed by
+// the engine cookie keys. The value is readable by clients but can't be
+// modified. See Engine.SetCookieKeys.
+func (p *Context) SetSignedCookie(c *http.Cookie) {
+	sc := *c
+	sc.Value = p.engine.CookieCodec().Sign(c.Name, []byte(c.Value))
+	http.SetCookie(p.ResponseWriter, &sc)
+}
+
+// SignedCookie returns the value of a cookie set by SetSignedCookie. If
+// maxAge is specified, cookies signed more than maxAge ago are rejected.
+func (p *Context) SignedCookie(name string, maxAge ...time.Duration) (string, error) {
+	c, err := p.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	b, err := p.engine.CookieCodec().Verify(name, c.Value, optDuration(maxAge))
+	return string(b), err
+}
+
+// SetSecureCookie adds a Set-Cookie header whose value is c.Value encrypted
+// by the engine cookie keys. See Engine.SetCookieKeys.
+func (p *Context) SetSecureCookie(c *http.Cookie) {
+	sc := *c
+	sc.Value = p.engine.CookieCodec().Encrypt(c.Name, []byte(c.Value))
+	http.SetCookie(p.ResponseWriter, &sc)
+}
+
+// SecureCookie returns the value of a cookie set by SetSecureCookie. If
+// maxAge is specified, cookies encrypted more than maxAge ago are rejected.
+func (p *Context) SecureCookie(name string, maxAge ...time.Duration) (string, error) {
+	c, err := p.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	b, err := p.engine.CookieCodec().Decrypt(name, c.Value, optDuration(maxAge))
+	return string(b), err
+}
+
+func optDuration(d []time.Duration) time.Duration {
+	if d != nil {
+		return d[0]
+	}
+	return 0
+}
+
+// -----------------------------------------------------------------------------
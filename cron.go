@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// cronSchedule represents a cron expression. Each field is a bit set of
+// allowed values.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+type cronField struct {
+	min, max int
+	names    []string // names of values from min, eg. jan, feb, ...
+}
+
+var cronFields = [5]cronField{
+	{0, 59, nil},
+	{0, 23, nil},
+	{1, 31, nil},
+	{1, 12, []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{0, 7, []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCron parses a cron expression of 5 fields (minute, hour, day of
+// month, month and day of week), eg. "*/5 * * * *" or "0 3 * * mon-fri", or
+// a descriptor like "@daily".
+func parseCron(spec string) (*cronSchedule, error) {
+	if d, ok := cronDescriptors[spec]; ok {
+		spec = d
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.New("cron: expected 5 fields in " + strconv.Quote(spec))
+	}
+	var bits [5]uint64
+	for i, f := range fields {
+		b, err := parseCronField(f, cronFields[i])
+		if err != nil {
+			return nil, errors.New("cron: " + err.Error() + " in " + strconv.Quote(spec))
+		}
+		bits[i] = b
+	}
+	if bits[4]&(1<<7) != 0 { // 7 is Sunday too
+		bits[4] |= 1
+	}
+	return &cronSchedule{
+		minute: bits[0], hour: bits[1], dom: bits[2], month: bits[3], dow: bits[4],
+		domStar: fields[2] == "*", dowStar: fields[4] == "*",
+	}, nil
+}
+
+func parseCronField(s string, f cronField) (bits uint64, err error) {
+	for _, part := range strings.Split(s, ",") {
+		rng, step, hasStep := strings.Cut(part, "/")
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+			if lo, err = cronValue(from, f); err != nil {
+				return
+			}
+			hi = lo
+			if isRange {
+				if hi, err = cronValue(to, f); err != nil {
+					return
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+			if lo > hi {
+				return 0, errors.New("bad range " + strconv.Quote(part))
+			}
+		}
+		n := 1
+		if hasStep {
+			if n, err = strconv.Atoi(step); err != nil || n <= 0 {
+				return 0, errors.New("bad step " + strconv.Quote(part))
+			}
+		}
+		for v := lo; v <= hi; v += n {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(s string, f cronField) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, errors.New("bad value " + strconv.Quote(s))
+	}
+	return v, nil
+}
+
+// next returns the first time matching the schedule after t, or the zero time
+// if there isn't one in 5 years.
+func (p *cronSchedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	end := t.AddDate(5, 0, 0)
+	for t.Before(end) {
+		switch {
+		case p.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !p.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case p.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case p.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether the day of t matches. As in cron, if both day of
+// month and day of week are restricted, either of them matches.
+func (p *cronSchedule) dayMatches(t time.Time) bool {
+	dom := p.dom&(1<<uint(t.Day())) != 0
+	dow := p.dow&(1<<uint(t.Weekday())) != 0
+	if p.domStar || p.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// -----------------------------------------------------------------------------
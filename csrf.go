@@ -0,0 +1,194 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+)
+
+// CSRFOptions represents options of the CSRF middleware.
+type CSRFOptions struct {
+	CookieName string        // name of the token cookie (default is "_csrf")
+	HeaderName string        // request header carrying the token (default is "X-CSRF-Token")
+	FieldName  string        // form field carrying the token (default is "_csrf")
+	Path       string        // cookie path (default is "/")
+	Domain     string        // cookie domain
+	MaxAge     time.Duration // token lifetime (default is 12 hours)
+	Secure     bool          // send the token cookie only over HTTPS
+	SameSite   http.SameSite // SameSite attribute of the cookie (default is Lax)
+
+	// ErrorHandler is called when the token is missing or invalid. If it is
+	// nil, a 403 Forbidden error is returned.
+	ErrorHandler http.Handler
+
+	// Skip reports whether a request is exempted from CSRF checking.
+	Skip func(r *http.Request) bool
+}
+
+const (
+	DefaultCSRFCookie = "_csrf"
+	DefaultCSRFHeader = "X-CSRF-Token"
+	DefaultCSRFField  = "_csrf"
+)
+
+func (p *CSRFOptions) init() {
+	if p.CookieName == "" {
+		p.CookieName = DefaultCSRFCookie
+	}
+	if p.HeaderName == "" {
+		p.HeaderName = DefaultCSRFHeader
+	}
+	if p.FieldName == "" {
+		p.FieldName = DefaultCSRFField
+	}
+	if p.Path == "" {
+		p.Path = "/"
+	}
+	if p.MaxAge <= 0 {
+		p.MaxAge = 12 * time.Hour
+	}
+	if p.SameSite == 0 {
+		p.SameSite = http.SameSiteLaxMode
+	}
+}
+
+type csrfKey struct{}
+
+const csrfTokenLen = 32
+
+// CSRF returns a middleware which protects requests against Cross-Site Request
+// Forgery by the double-submit cookie pattern: a random token is stored in a
+// cookie, and requests with unsafe methods (other than GET, HEAD, OPTIONS and
+// TRACE) must send the same token in a header or a form field.
+//
+// The form field of a multipart request is read by parsing the form as
+// ctx.MultipartForm does (with the default UploadOptions; UseCSRF uses those
+// of the engine), so the handler gets the parsed form instead of the body.
+// Handlers streaming the body by Request.MultipartReader should require the
+// token in the header.
+func CSRF(opts ...CSRFOptions) func(h http.Handler) http.Handler {
+	var o CSRFOptions
+	if opts != nil {
+		o = opts[0]
+	}
+	o.init()
+	return csrf(o, new(UploadOptions))
+}
+
+func csrf(o CSRFOptions, uploads *UploadOptions) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := ""
+			if c, err := r.Cookie(o.CookieName); err == nil && validCSRFToken(c.Value) {
+				token = c.Value
+			}
+			if !isSafeMethod(r.Method) && (o.Skip == nil || !o.Skip(r)) {
+				sent := r.Header.Get(o.HeaderName)
+				if sent == "" {
+					if isMultipart(r) && requestUploads(r) == nil {
+						st := new(uploadState)
+						st.form, st.formErr = parseMultipart(r, *uploads)
+						defer st.removeAll()
+						r = r.WithContext(context.WithValue(r.Context(), uploadKey{}, st))
+					}
+					sent = r.PostFormValue(o.FieldName)
+				}
+				if token == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+					if o.ErrorHandler != nil {
+						o.ErrorHandler.ServeHTTP(w, r)
+					} else {
+						http.Error(w, "Forbidden - invalid CSRF token", http.StatusForbidden)
+					}
+					return
+				}
+			}
+			if token == "" {
+				token = newCSRFToken()
+				http.SetCookie(w, &http.Cookie{
+					Name:     o.CookieName,
+					Value:    token,
+					Path:     o.Path,
+					Domain:   o.Domain,
+					MaxAge:   int(o.MaxAge / time.Second),
+					Secure:   o.Secure,
+					HttpOnly: true,
+					SameSite: o.SameSite,
+				})
+			}
+			w.Header().Add("Vary", "Cookie")
+			h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), csrfKey{}, token)))
+		})
+	}
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func newCSRFToken() string {
+	var b [csrfTokenLen]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		log.Panicln("CSRF:", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
+
+func validCSRFToken(token string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	return err == nil && len(b) == csrfTokenLen
+}
+
+// UseCSRF adds the CSRF middleware to the engine (see CSRF). It also adds two
+// template functions: `csrfToken` returns the token, and `csrfField` returns a
+// hidden input element carrying the token, to be used in html forms.
+func (p *Engine) UseCSRF(opts ...CSRFOptions) {
+	var o CSRFOptions
+	if opts != nil {
+		o = opts[0]
+	}
+	o.init()
+	p.Use(csrf(o, &p.uploads))
+	p.ctxFunc("csrfToken", func(ctx *Context) any {
+		return func() string {
+			return ctx.CSRFToken()
+		}
+	})
+	p.ctxFunc("csrfField", func(ctx *Context) any {
+		return func() template.HTML {
+			return template.HTML(`<input type="hidden" name="` +
+				template.HTMLEscapeString(o.FieldName) + `" value="` + ctx.CSRFToken() + `">`)
+		}
+	})
+}
+
+// CSRFToken returns the CSRF token of the request. It returns an empty string
+// if the CSRF middleware is not used.
+func (p *Context) CSRFToken() string {
+	token, _ := p.Request.Context().Value(csrfKey{}).(string)
+	return token
+}
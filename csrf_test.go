@@ -0,0 +1,225 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/goplus/yap"
+)
+
+func csrfEngine(opts ...yap.CSRFOptions) *yap.Engine {
+	e := yap.New(fstest.MapFS{
+		"form_yap.html": {Data: []byte(`<form>{{csrfField}}</form>`)},
+	})
+	e.UseCSRF(opts...)
+	e.GET("/form", func(ctx *yap.Context) {
+		ctx.YAP(200, "form", nil)
+	})
+	e.POST("/articles", func(ctx *yap.Context) {
+		ctx.TEXT(200, "text/plain", "created "+ctx.Param("title"))
+	})
+	return e
+}
+
+func TestCSRF(t *testing.T) {
+	e := csrfEngine()
+	w := serve(e, "GET", "/form")
+	c := respCookie(w, yap.DefaultCSRFCookie)
+	if c == nil {
+		t.Fatal("CSRF: no token cookie")
+	}
+	if body := w.Body.String(); !strings.Contains(body, `name="_csrf" value="`+c.Value+`"`) {
+		t.Fatal("csrfField:", body)
+	}
+
+	// missing token
+	if w = serve(e, "POST", "/articles", c); w.Code != http.StatusForbidden {
+		t.Fatal("POST without token:", w.Code)
+	}
+
+	// token in form field
+	form := url.Values{"_csrf": {c.Value}, "title": {"hi"}}
+	req := httptest.NewRequest("POST", "/articles", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(c)
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Code != 200 || w.Body.String() != "created hi" {
+		t.Fatal("POST with form token:", w.Code, w.Body.String())
+	}
+
+	// token in header
+	req = httptest.NewRequest("POST", "/articles", nil)
+	req.Header.Set(yap.DefaultCSRFHeader, c.Value)
+	req.AddCookie(c)
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Code != 200 {
+		t.Fatal("POST with header token:", w.Code)
+	}
+
+	// mismatched token
+	req = httptest.NewRequest("POST", "/articles", nil)
+	req.Header.Set(yap.DefaultCSRFHeader, "bad")
+	req.AddCookie(c)
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatal("POST with bad token:", w.Code)
+	}
+}
+
+func TestCSRFErrorHandler(t *testing.T) {
+	e := csrfEngine(yap.CSRFOptions{
+		ErrorHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(419)
+		}),
+		Skip: func(r *http.Request) bool {
+			return r.URL.Query().Get("skip") == "1"
+		},
+	})
+	if w := serve(e, "POST", "/articles"); w.Code != 419 {
+		t.Fatal("CSRF ErrorHandler:", w.Code)
+	}
+	if w := serve(e, "POST", "/articles?skip=1"); w.Code != 200 {
+		t.Fatal("CSRF Skip:", w.Code)
+	}
+}
+
+func TestEngineUse(t *testing.T) {
+	e := newEngine()
+	var order []string
+	for _, name := range []string{"a", "b"} {
+		e.Use(func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				h.ServeHTTP(w, r)
+			})
+		})
+	}
+	e.GET("/", func(ctx *yap.Context) {
+		order = append(order, "handler")
+	})
+	serve(e, "GET", "/")
+	if strings.Join(order, ",") != "a,b,handler" {
+		t.Fatal("Use:", order)
+	}
+}
+
+func TestCSRFTemplateConcurrent(t *testing.T) {
+	e := csrfEngine()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				w := serve(e, "GET", "/form")
+				c := respCookie(w, yap.DefaultCSRFCookie)
+				if c == nil || !strings.Contains(w.Body.String(), `value="`+c.Value+`"`) {
+					t.Error("csrfField: token of another request", w.Body.String())
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTemplateFuncsAfterRender(t *testing.T) {
+	e := yap.New(fstest.MapFS{
+		"page_yap.html": {Data: []byte(`<p>{{.}}</p>`)},
+	})
+	e.GET("/page", func(ctx *yap.Context) {
+		ctx.YAP(200, "page", "hi")
+	})
+	if w := serve(e, "GET", "/page"); w.Body.String() != "<p>hi</p>" {
+		t.Fatal("YAP:", w.Body.String())
+	}
+	e.UseCSRF() // adds template functions after the templates are executed
+	if w := serve(e, "GET", "/page"); w.Body.String() != "<p>hi</p>" {
+		t.Fatal("YAP:", w.Body.String())
+	}
+}
+
+func TestCSRFMultipart(t *testing.T) {
+	tmp := t.TempDir()
+	e := csrfEngine()
+	e.SetUploadOptions(yap.UploadOptions{MaxMemory: 16, TempDir: tmp})
+	e.POST("/upload", func(ctx *yap.Context) {
+		f, err := ctx.FormFile("file")
+		if err != nil {
+			t.Fatal("FormFile:", err)
+		}
+		ctx.Text__2(ctx.Param("title") + " " + f.Filename)
+	})
+	e.POST("/stream", func(ctx *yap.Context) {
+		mr, err := ctx.MultipartReader()
+		if err != nil {
+			t.Fatal("MultipartReader:", err)
+		}
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatal("NextPart:", err)
+		}
+		ctx.Text__2(part.FormName())
+	})
+	c := respCookie(serve(e, "GET", "/form"), yap.DefaultCSRFCookie)
+
+	post := func(path string, withField bool, header ...string) *httptest.ResponseRecorder {
+		var b bytes.Buffer
+		mw := multipart.NewWriter(&b)
+		mw.WriteField("title", "hi")
+		if withField {
+			mw.WriteField("_csrf", c.Value)
+		}
+		fw, _ := mw.CreateFormFile("file", "a.png")
+		fw.Write(pngData)
+		mw.Close()
+		req := httptest.NewRequest("POST", path, &b)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		if header != nil {
+			req.Header.Set(header[0], header[1])
+		}
+		req.AddCookie(c)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w
+	}
+	if w := post("/upload", true); w.Code != 200 || w.Body.String() != "hi a.png" {
+		t.Fatal("multipart field token:", w.Code, w.Body.String())
+	}
+	if entries, _ := os.ReadDir(tmp); len(entries) != 0 {
+		t.Fatal("temp files not removed:", len(entries))
+	}
+	if w := post("/upload", false); w.Code != http.StatusForbidden {
+		t.Fatal("multipart without token:", w.Code)
+	}
+	if w := post("/stream", false, yap.DefaultCSRFHeader, c.Value); w.Code != 200 || w.Body.String() != "title" {
+		t.Fatal("multipart header token:", w.Code, w.Body.String())
+	}
+}
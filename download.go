@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// ServeContent replies to the request using the content, with support of
+// Range requests (single and multiple ranges), If-Range and conditional
+// requests. See http.ServeContent.
+//
+// If the Content-Type header isn't set, it is determined by the extension of
+// name, or sniffed from the content.
+func (p *Context) ServeContent(name string, modtime time.Time, content io.ReadSeeker) {
+	http.ServeContent(p.ResponseWriter, p.Request, name, modtime, content)
+}
+
+// Attachment replies to the request with the content as a file to download
+// named filename. Range requests are supported (so downloads can be resumed)
+// if the content is an io.ReadSeeker which can seek (unlike a pipe), and the
+// content from its current offset is replied, eg.
+//
+//	f, err := os.Open(path)
+//	...
+//	defer f.Close()
+//	ctx.Attachment("report 2026.pdf", f, info.ModTime())
+func (p *Context) Attachment(filename string, content io.Reader, modtime ...time.Time) {
+	p.ResponseWriter.Header().Set("Content-Disposition", ContentDisposition("attachment", filename))
+	if rs, ok := content.(io.ReadSeeker); ok {
+		if off, err := rs.Seek(0, io.SeekCurrent); err == nil {
+			var mt time.Time
+			if modtime != nil {
+				mt = modtime[0]
+			}
+			if off != 0 {
+				rs = &offsetSeeker{rs, off}
+			}
+			p.ServeContent(filename, mt, rs)
+			return
+		}
+	}
+	p.ResponseWriter.Header().Set("Accept-Ranges", "none")
+	p.STREAM(http.StatusOK, "", content, nil)
+}
+
+// offsetSeeker is an io.ReadSeeker of the content after offset base.
+type offsetSeeker struct {
+	io.ReadSeeker
+	base int64
+}
+
+func (p *offsetSeeker) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekStart {
+		offset += p.base
+	}
+	n, err := p.ReadSeeker.Seek(offset, whence)
+	return n - p.base, err
+}
+
+// ContentDisposition returns a Content-Disposition header value of
+// disposition ("attachment" or "inline") and filename, encoded as RFC 6266
+// specifies: an ASCII fallback in filename, and the UTF-8 name in filename*
+// if needed, eg.
+//
+//	attachment; filename="r_sum_.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf
+func ContentDisposition(disposition, filename string) string {
+	var fallback, ext strings.Builder
+	needExt := false
+	for i := 0; i < len(filename); i++ {
+		c := filename[i]
+		if isAttrChar(c) {
+			ext.WriteByte(c)
+		} else {
+			ext.WriteString("%")
+			ext.WriteByte(upperHex[c>>4])
+			ext.WriteByte(upperHex[c&15])
+		}
+	}
+	for _, r := range filename {
+		switch {
+		case r == '"' || r == '\\' || r == '%':
+			fallback.WriteByte('_')
+			needExt = true
+		case r < 0x20 || r >= 0x7f:
+			fallback.WriteByte('_')
+			needExt = true
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+	ret := disposition + `; filename="` + fallback.String() + `"`
+	if needExt {
+		ret += "; filename*=UTF-8''" + ext.String()
+	}
+	return ret
+}
+
+const upperHex = "0123456789ABCDEF"
+
+// isAttrChar reports whether c is an attr-char of RFC 8187.
+func isAttrChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/goplus/yap"
+)
+
+func TestContentDisposition(t *testing.T) {
+	for _, c := range []struct{ name, want string }{
+		{"report.pdf", `attachment; filename="report.pdf"`},
+		{"my report.pdf", `attachment; filename="my report.pdf"`},
+		{"résumé.pdf", `attachment; filename="r_sum_.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf`},
+		{`a"b\c%.txt`, `attachment; filename="a_b_c_.txt"; filename*=UTF-8''a%22b%5Cc%25.txt`},
+	} {
+		if got := yap.ContentDisposition("attachment", c.name); got != c.want {
+			t.Fatal("ContentDisposition:", got)
+		}
+	}
+}
+
+func TestRangeRequests(t *testing.T) {
+	const data = "0123456789abcdef"
+	e := yap.New()
+	e.GET("/file", func(ctx *yap.Context) {
+		ctx.Attachment("数据.txt", strings.NewReader(data))
+	})
+	e.GET("/stream", func(ctx *yap.Context) {
+		ctx.STREAM(200, "text/plain", strings.NewReader(data), nil)
+	})
+	e.GET("/pipe", func(ctx *yap.Context) {
+		ctx.Attachment("pipe.txt", io.MultiReader(strings.NewReader(data)))
+	})
+	e.GET("/rest", func(ctx *yap.Context) {
+		r := strings.NewReader(data)
+		r.Seek(10, io.SeekStart) // eg. a header is read
+		ctx.Attachment("rest.txt", r)
+	})
+
+	w := serveWith(e, "GET", "/file")
+	if w.Code != 200 || w.Body.String() != data || w.Header().Get("Accept-Ranges") != "bytes" ||
+		w.Header().Get("Content-Type") != "text/plain; charset=utf-8" ||
+		!strings.Contains(w.Header().Get("Content-Disposition"), "filename*=UTF-8''%E6%95%B0%E6%8D%AE.txt") {
+		t.Fatal("GET /file:", w.Code, w.Header())
+	}
+	w = serveWith(e, "GET", "/file", "Range", "bytes=2-5")
+	if w.Code != 206 || w.Body.String() != "2345" || w.Header().Get("Content-Range") != "bytes 2-5/16" {
+		t.Fatal("single range:", w.Code, w.Body.String())
+	}
+	w = serveWith(e, "GET", "/rest", "Range", "bytes=2-")
+	if w.Code != 206 || w.Body.String() != "cdef" || w.Header().Get("Content-Range") != "bytes 2-5/6" {
+		t.Fatal("from offset:", w.Code, w.Body.String(), w.Header().Get("Content-Range"))
+	}
+	w = serveWith(e, "GET", "/stream", "Range", "bytes=2-5")
+	if w.Code != 200 || w.Body.String() != data {
+		t.Fatal("STREAM:", w.Code, w.Body.String())
+	}
+	w = serveWith(e, "GET", "/file", "Range", "bytes=0-1,-2")
+	if body := w.Body.String(); w.Code != 206 ||
+		!strings.HasPrefix(w.Header().Get("Content-Type"), "multipart/byteranges; boundary=") ||
+		!strings.Contains(body, "\r\n\r\n01\r\n") || !strings.Contains(body, "\r\n\r\nef\r\n") {
+		t.Fatal("multiple ranges:", w.Code, body)
+	}
+
+	w = serveWith(e, "GET", "/pipe", "Range", "bytes=2-5")
+	if w.Code != 200 || w.Body.String() != data || w.Header().Get("Accept-Ranges") != "none" {
+		t.Fatal("not seekable:", w.Code, w.Header())
+	}
+}
+
+func TestStreamPipe(t *testing.T) {
+	r, pw, err := os.Pipe() // an *os.File which can't seek
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	go func() {
+		pw.WriteString("hello\n")
+		pw.Close()
+	}()
+	e := yap.New()
+	e.GET("/out", func(ctx *yap.Context) {
+		ctx.STREAM(200, "text/plain", r, nil)
+	})
+	if w := serveWith(e, "GET", "/out", "Range", "bytes=0-1"); w.Code != 200 || w.Body.String() != "hello\n" {
+		t.Fatal("STREAM pipe:", w.Code, w.Body.String())
+	}
+
+	r2, pw2, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r2.Close()
+	go func() {
+		pw2.WriteString("log")
+		pw2.Close()
+	}()
+	e.GET("/log", func(ctx *yap.Context) {
+		ctx.Attachment("out.log", r2)
+	})
+	if w := serveWith(e, "GET", "/log"); w.Code != 200 || w.Body.String() != "log" || w.Header().Get("Accept-Ranges") != "none" {
+		t.Fatal("Attachment pipe:", w.Code, w.Body.String(), w.Header())
+	}
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+type healthCheck struct {
+	name      string
+	check     func(ctx context.Context) error
+	readiness bool // only checked by the readiness endpoint
+}
+
+// HealthOptions represents options of UseHealth.
+type HealthOptions struct {
+	// HealthPath is the path of the liveness endpoint. It is "/healthz" by
+	// default.
+	HealthPath string
+
+	// ReadyPath is the path of the readiness endpoint. It is "/readyz" by
+	// default.
+	ReadyPath string
+
+	// Timeout is the timeout of each check. It is 5 seconds by default.
+	Timeout time.Duration
+
+	// DrainDelay is how long Shutdown waits after the readiness endpoint
+	// reports "draining" before the server stops accepting new connections,
+	// so load balancers notice it first. It is 0 (no delay) by default.
+	DrainDelay time.Duration
+}
+
+// UseHealth registers the liveness (/healthz) and readiness (/readyz)
+// endpoints. They run registered checks (see AddHealthCheck and
+// AddReadyCheck) and report a JSON status, eg.
+//
+//	{"status": "fail", "checks": {"db": {"status": "fail", "error": "..."}}}
+//
+// with 200 OK if all checks pass, or 503 Service Unavailable otherwise. The
+// readiness endpoint also reports "draining" with 503 once Shutdown is called.
+func (p *Engine) UseHealth(opts ...HealthOptions) {
+	var o HealthOptions
+	if opts != nil {
+		o = opts[0]
+	}
+	if o.HealthPath == "" {
+		o.HealthPath = "/healthz"
+	}
+	if o.ReadyPath == "" {
+		o.ReadyPath = "/readyz"
+	}
+	if o.Timeout == 0 {
+		o.Timeout = 5 * time.Second
+	}
+	p.drainDelay = o.DrainDelay
+	p.router.GET(o.HealthPath, func(ctx *Context) {
+		p.serveHealth(ctx, o.Timeout, false)
+	})
+	p.router.GET(o.ReadyPath, func(ctx *Context) {
+		p.serveHealth(ctx, o.Timeout, true)
+	})
+}
+
+// AddHealthCheck registers a check run by both the liveness and readiness
+// endpoints. See UseHealth.
+func (p *Engine) AddHealthCheck(name string, check func(ctx context.Context) error) {
+	p.checks = append(p.checks, healthCheck{name: name, check: check})
+}
+
+// AddReadyCheck registers a check run by the readiness endpoint only, eg.
+// pinging the database:
+//
+//	y.AddReadyCheck("db", db.PingContext)
+func (p *Engine) AddReadyCheck(name string, check func(ctx context.Context) error) {
+	p.checks = append(p.checks, healthCheck{name: name, check: check, readiness: true})
+}
+
+type checkResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (p *Engine) serveHealth(ctx *Context, timeout time.Duration, readiness bool) {
+	results := make(map[string]checkResult)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	failed := false
+	for _, c := range p.checks {
+		if c.readiness && !readiness {
+			continue
+		}
+		wg.Add(1)
+		go func(c healthCheck) {
+			defer wg.Done()
+			cctx, cancel := context.WithTimeout(ctx.Context(), timeout)
+			defer cancel()
+			ret := checkResult{Status: "ok"}
+			if err := c.check(cctx); err != nil {
+				ret = checkResult{Status: "fail", Error: err.Error()}
+			}
+			mutex.Lock()
+			results[c.name] = ret
+			failed = failed || ret.Status != "ok"
+			mutex.Unlock()
+		}(c)
+	}
+	wg.Wait()
+
+	code, status := http.StatusOK, "ok"
+	if failed {
+		code, status = http.StatusServiceUnavailable, "fail"
+	} else if readiness && p.draining.Load() {
+		code, status = http.StatusServiceUnavailable, "draining"
+	}
+	ctx.ResponseWriter.Header().Set("Cache-Control", "no-store")
+	ret := H{"status": status}
+	if len(results) > 0 {
+		ret["checks"] = results
+	}
+	ctx.JSON(code, ret)
+}
+
+// -----------------------------------------------------------------------------
+
+// Shutdown gracefully shuts down the server started by Run: the readiness
+// endpoint (see UseHealth) reports "draining" at once, and after
+// HealthOptions.DrainDelay (or when ctx is done), the server stops accepting
+// new connections and waits for active requests until ctx is done.
+// Background jobs (see Every and Enqueue) are cancelled, and running ones are
+// waited for until ctx is done too. Run returns nil after Shutdown.
+//
+// For example, to drain on SIGTERM:
+//
+//	y.UseHealth(yap.HealthOptions{DrainDelay: 5 * time.Second}) // let load balancers notice
+//	go func() {
+//		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+//		defer stop()
+//		<-ctx.Done()
+//		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//		defer cancel()
+//		y.Shutdown(ctx)
+//	}()
+func (p *Engine) Shutdown(ctx context.Context) error {
+	p.draining.Store(true)
+	var err error
+	if srv := p.srv.Load(); srv != nil {
+		if p.drainDelay > 0 {
+			t := time.NewTimer(p.drainDelay)
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+			}
+			t.Stop()
+		}
+		err = srv.Shutdown(ctx)
+	}
+	return errors.Join(err, p.jobs.stop(ctx))
+}
+
+// Draining reports whether Shutdown is called.
+func (p *Engine) Draining() bool {
+	return p.draining.Load()
+}
+
+// -----------------------------------------------------------------------------
+
+// UsePprof mounts net/http/pprof handlers at /debug/pprof/. Profiles expose
+// internals of the process, so at least one policy is required to protect
+// them, eg.
+//
+//	y.UsePprof(yap.RequireRoles("admin"))
+func (p *Engine) UsePprof(policies ...*Policy) {
+	if len(policies) == 0 {
+		log.Panicln("UsePprof: no policy to protect /debug/pprof/")
+	}
+	g := p.Group("/debug/pprof", policies...)
+	for _, e := range []struct {
+		pattern string
+		h       http.HandlerFunc
+	}{
+		{"/", pprof.Index},
+		{"/cmdline", pprof.Cmdline},
+		{"/profile", pprof.Profile},
+		{"/symbol", pprof.Symbol},
+		{"/trace", pprof.Trace},
+	} {
+		h := e.h
+		g.Handle(e.pattern, func(ctx *Context) {
+			h(ctx.ResponseWriter, ctx.Request)
+		})
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,173 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/goplus/yap"
+)
+
+func healthStatus(t *testing.T, e *yap.Engine, path string) (int, map[string]any) {
+	t.Helper()
+	w := serve(e, "GET", path)
+	var ret map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &ret); err != nil {
+		t.Fatal(path, err, w.Body.String())
+	}
+	return w.Code, ret
+}
+
+func TestHealth(t *testing.T) {
+	e := newEngine()
+	e.UseHealth(yap.HealthOptions{Timeout: 10 * time.Millisecond})
+	if code, ret := healthStatus(t, e, "/healthz"); code != 200 || ret["status"] != "ok" {
+		t.Fatal("healthz:", code, ret)
+	}
+
+	var dbErr error
+	e.AddHealthCheck("self", func(ctx context.Context) error { return nil })
+	e.AddReadyCheck("db", func(ctx context.Context) error { return dbErr })
+	e.AddReadyCheck("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	code, ret := healthStatus(t, e, "/readyz")
+	checks := ret["checks"].(map[string]any)
+	if code != 503 || ret["status"] != "fail" || len(checks) != 3 ||
+		checks["slow"].(map[string]any)["error"] != "context deadline exceeded" {
+		t.Fatal("readyz:", code, ret)
+	}
+	if code, ret := healthStatus(t, e, "/healthz"); code != 200 || len(ret["checks"].(map[string]any)) != 1 {
+		t.Fatal("healthz checks:", code, ret)
+	}
+
+	dbErr = errors.New("db down")
+	e = newEngine()
+	e.UseHealth()
+	e.AddReadyCheck("db", func(ctx context.Context) error { return nil })
+	if code, _ := healthStatus(t, e, "/readyz"); code != 200 {
+		t.Fatal("readyz:", code)
+	}
+	if err := e.Shutdown(context.Background()); err != nil || !e.Draining() {
+		t.Fatal("Shutdown:", err)
+	}
+	if code, ret := healthStatus(t, e, "/readyz"); code != 503 || ret["status"] != "draining" {
+		t.Fatal("readyz draining:", code, ret)
+	}
+	if code, _ := healthStatus(t, e, "/healthz"); code != 200 {
+		t.Fatal("healthz draining:", code)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	e := yap.New()
+	done := make(chan error)
+	go func() {
+		done <- e.Run("127.0.0.1:0")
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal("Shutdown:", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal("Run:", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run doesn't return after Shutdown")
+	}
+}
+
+func TestShutdownDrainDelay(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	e := yap.New()
+	e.UseHealth(yap.HealthOptions{DrainDelay: 300 * time.Millisecond})
+	done := make(chan error, 1)
+	go func() {
+		done <- e.Run(addr)
+	}()
+	ready := func() int {
+		resp, err := http.Get("http://" + addr + "/readyz")
+		if err != nil {
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	for i := 0; ready() != 200; i++ {
+		if i == 100 {
+			t.Fatal("server not started")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	start := time.Now()
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- e.Shutdown(context.Background())
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Fatal("readyz while draining:", code)
+	}
+	select {
+	case <-done:
+		t.Fatal("Run returns before DrainDelay")
+	default:
+	}
+	if err := <-shutdown; err != nil {
+		t.Fatal("Shutdown:", err)
+	}
+	if d := time.Since(start); d < 300*time.Millisecond {
+		t.Fatal("Shutdown returns before DrainDelay:", d)
+	}
+	if err := <-done; err != nil {
+		t.Fatal("Run:", err)
+	}
+}
+
+func TestPprof(t *testing.T) {
+	e := newEngine()
+	e.Use(yap.Auth(yap.AuthOptions{}, users))
+	e.UsePprof(yap.RequireRoles("admin"))
+	if code := serveAs(e, "GET", "/debug/pprof/", ""); code != 401 {
+		t.Fatal("pprof anonymous:", code)
+	}
+	if code := serveAs(e, "GET", "/debug/pprof/cmdline", "admin"); code != 200 {
+		t.Fatal("pprof admin:", code)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("UsePprof without policies should panic")
+		}
+	}()
+	newEngine().UsePprof()
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"sync"
+)
+
+// -----------------------------------------------------------------------------
+
+// services is a registry of services injected into fields of YAP handlers.
+type services struct {
+	list     []reflect.Value
+	plans    sync.Map // reflect.Type => []injectField
+	withCtxs sync.Map // reflect.Type => index of its WithContext method, or -1
+}
+
+type injectField struct {
+	index int
+	typ   reflect.Type
+	name  string
+}
+
+// Provide registers services (eg. a *sql.DB, a config or a client) which are
+// injected into exported fields tagged with `yap:"inject"` of YAP handlers when
+// they are cloned for a request, eg.
+//
+//	type get_p_id struct {
+//		yap.Handler
+//		*AppV2
+//		DB *sql.DB `yap:"inject"`
+//	}
+//
+// A field is injected with the service of the same type, or the only service
+// assignable to it (eg. an interface). A service provided again with the same
+// type replaces the old one. Services should be provided before serving
+// requests.
+//
+// A service of type T with a method WithContext(ctx context.Context) T (eg. a
+// *ydb.Class) is injected by the result of WithContext with the request
+// context, ie. a copy of the service bound to the request.
+func (p *Engine) Provide(services ...any) {
+	for _, svc := range services {
+		if svc == nil {
+			log.Panicln("Provide: nil service")
+		}
+		v := reflect.ValueOf(svc)
+		if i := p.services.index(v.Type()); i >= 0 {
+			p.services.list[i] = v
+		} else {
+			p.services.list = append(p.services.list, v)
+		}
+	}
+}
+
+func (p *services) index(typ reflect.Type) int {
+	for i, v := range p.list {
+		if v.Type() == typ {
+			return i
+		}
+	}
+	return -1
+}
+
+// lookup returns the service of type typ, or the only service assignable to
+// typ.
+func (p *services) lookup(typ reflect.Type) (ret reflect.Value, ok bool) {
+	if i := p.index(typ); i >= 0 {
+		return p.list[i], true
+	}
+	for _, v := range p.list {
+		if v.Type().AssignableTo(typ) {
+			if ok {
+				log.Panicln("yap: ambiguous services for type", typ)
+			}
+			ret, ok = v, true
+		}
+	}
+	return
+}
+
+// plan returns fields to be injected of a handler type (a pointer to struct),
+// which are cached per type. A tagged field must be exported.
+func (p *services) plan(typ reflect.Type) []injectField {
+	if v, ok := p.plans.Load(typ); ok {
+		return v.([]injectField)
+	}
+	var fields []injectField
+	if typ.Kind() == reflect.Pointer && typ.Elem().Kind() == reflect.Struct {
+		t := typ.Elem()
+		for i, n := 0, t.NumField(); i < n; i++ {
+			if f := t.Field(i); f.Tag.Get("yap") == "inject" {
+				if !f.IsExported() {
+					log.Panicln("yap: field", f.Name, "of", t, "tagged with `yap:\"inject\"` must be exported")
+				}
+				fields = append(fields, injectField{index: i, typ: f.Type, name: f.Name})
+			}
+		}
+	}
+	p.plans.Store(typ, fields)
+	return fields
+}
+
+// withContext returns svc.WithContext(ctx) if svc has such a method returning
+// a value of its type (see Provide), otherwise svc.
+func (p *services) withContext(svc reflect.Value, ctx context.Context) reflect.Value {
+	typ := svc.Type()
+	idx, ok := p.withCtxs.Load(typ)
+	if !ok {
+		idx = -1
+		if m, ok := typ.MethodByName("WithContext"); ok {
+			if mt := m.Type; mt.NumIn() == 2 && mt.In(1) == tyContextIntf && mt.NumOut() == 1 && mt.Out(0) == typ {
+				idx = m.Index
+			}
+		}
+		p.withCtxs.Store(typ, idx)
+	}
+	if i := idx.(int); i >= 0 {
+		return svc.Method(i).Call([]reflect.Value{reflect.ValueOf(ctx)})[0]
+	}
+	return svc
+}
+
+var tyContextIntf = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// inject sets fields tagged with `yap:"inject"` of handler h, with services
+// bound to ctx (see Provide).
+func (p *services) inject(ctx context.Context, h any) {
+	fields := p.plan(reflect.TypeOf(h))
+	if fields == nil {
+		return
+	}
+	self := reflect.ValueOf(h).Elem()
+	for _, f := range fields {
+		svc, ok := p.lookup(f.typ)
+		if !ok {
+			log.Panicln("yap: no service of type", f.typ, "for field", f.name, "of", self.Type())
+		}
+		self.Field(f.index).Set(p.withContext(svc, ctx))
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// A YAP handler can run code before and after Main by defining Before and
+// After methods. Main isn't called if Before returns false, in which case
+// Before should write the response. After is called even if Main panics.
+type iHandlerBefore interface {
+	Before(ctx *Context) bool
+}
+
+type iHandlerAfter interface {
+	After(ctx *Context)
+}
+
+// runProto clones a handler from proto, injects services into it, and runs
+// it (replying its result, see iHandlerResult). It reports whether Main is
+// called.
+func runProto(ctx *Context, proto HandlerProto) bool {
+	h := proto.Classclone()
+	ctx.engine.services.inject(ctx.Request.Context(), h)
+	if b, ok := h.(iHandlerBefore); ok && !b.Before(ctx) {
+		return false
+	}
+	if a, ok := h.(iHandlerAfter); ok {
+		defer a.After(ctx)
+	}
+	h.Main(ctx)
+	replyHandlerResult(ctx, h)
+	return true
+}
+
+// -----------------------------------------------------------------------------
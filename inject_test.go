@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/goplus/yap"
+)
+
+type greeter interface {
+	Greet(name string) string
+}
+
+type helloGreeter struct{ prefix string }
+
+func (p *helloGreeter) Greet(name string) string {
+	return p.prefix + name
+}
+
+type appConfig struct{ Site string }
+
+type InjectAppV2 struct {
+	yap.AppV2
+	log []string
+}
+
+func (p *InjectAppV2) Main() {
+	yap.XGot_AppV2_Main(p, &injectHandler{fname: "get_hi_#name"})
+}
+
+func (p *InjectAppV2) MainEntry() {
+	p.Provide(&helloGreeter{prefix: "Hi, "}, &appConfig{Site: "yap"})
+}
+
+// injectHandler declares fields in an order different from generated code, so
+// its app field isn't the second one.
+type injectHandler struct {
+	yap.Handler
+	fname string
+	G     greeter    `yap:"inject"`
+	Cfg   *appConfig `yap:"inject"`
+	*InjectAppV2
+}
+
+func (p *injectHandler) Before(ctx *yap.Context) bool {
+	p.InjectAppV2.log = append(p.InjectAppV2.log, "before "+ctx.Param("name"))
+	if ctx.Param("name") == "nobody" {
+		ctx.TEXT(404, "text/plain", "nobody")
+		return false
+	}
+	return true
+}
+
+func (p *injectHandler) After(ctx *yap.Context) {
+	p.InjectAppV2.log = append(p.InjectAppV2.log, "after "+ctx.Param("name"))
+}
+
+func (p *injectHandler) Main(ctx *yap.Context) {
+	p.Handler.Main(ctx)
+	ctx.Text__2(p.G.Greet(ctx.Param("name")) + "@" + p.Cfg.Site)
+}
+
+func (p *injectHandler) Classfname() string {
+	return p.fname
+}
+
+func (p *injectHandler) Classclone() yap.HandlerProto {
+	ret := *p
+	return &ret
+}
+
+func TestInject(t *testing.T) {
+	app := new(InjectAppV2)
+	app.InitYap()
+	app.Main()
+	if w := serveWith(app, "GET", "/hi/ken"); w.Body.String() != "Hi, ken@yap" {
+		t.Fatal("GET /hi/ken:", w.Code, w.Body.String())
+	}
+	if w := serveWith(app, "GET", "/hi/nobody"); w.Code != 404 {
+		t.Fatal("GET /hi/nobody:", w.Code)
+	}
+	if got := strings.Join(app.log, ","); got != "before ken,after ken,before nobody" {
+		t.Fatal("log:", got)
+	}
+}
+
+func TestInjectErrors(t *testing.T) {
+	e := yap.New()
+	e.ProtoRoute("GET", "/hi/:name", &injectHandler{})
+	for _, c := range []struct {
+		services []any
+		want     string
+	}{
+		{nil, "no service of type yap_test.greeter for field G"},
+		{[]any{&helloGreeter{}, greeterFunc(nil)}, "ambiguous services for type yap_test.greeter"},
+	} {
+		e.Provide(c.services...)
+		func() {
+			defer func() {
+				if e := recover(); !strings.Contains(fmt.Sprint(e), c.want) {
+					t.Fatal("recover:", e)
+				}
+			}()
+			serveWith(e, "GET", "/hi/ken")
+		}()
+	}
+}
+
+type greeterFunc func(name string) string
+
+func (f greeterFunc) Greet(name string) string {
+	return f(name)
+}
+
+type unexportedInject struct {
+	yap.Handler
+	cfg *appConfig `yap:"inject"`
+}
+
+func (p *unexportedInject) Main(ctx *yap.Context) {
+	ctx.Text__2(p.cfg.Site)
+}
+
+func (p *unexportedInject) Classclone() yap.HandlerProto {
+	ret := *p
+	return &ret
+}
+
+func TestInjectUnexported(t *testing.T) {
+	e := yap.New()
+	e.Provide(&appConfig{Site: "yap"})
+	e.ProtoRoute("GET", "/site", &unexportedInject{})
+	defer func() {
+		if e := recover(); !strings.Contains(fmt.Sprint(e), "yap: field cfg of yap_test.unexportedInject tagged with `yap:\"inject\"` must be exported") {
+			t.Fatal("recover:", e)
+		}
+	}()
+	serveWith(e, "GET", "/site")
+}
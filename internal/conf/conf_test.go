@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	const doc = `---
+# server
+addr: ":8080"   # listen address
+debug: true
+db:
+  url: 'mysql://root@/blog?it''s'
+  max_conns: 10
+  hosts:
+  - a.local
+  - "b.local # not a comment"
+  tags: [x, "y, z"]
+empty:
+nothing: ~
+`
+	got, err := ParseYAML([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"addr":  ":8080",
+		"debug": "true",
+		"db": map[string]any{
+			"url":       "mysql://root@/blog?it's",
+			"max_conns": "10",
+			"hosts":     []any{"a.local", "b.local # not a comment"},
+			"tags":      []any{"x", "y, z"},
+		},
+		"empty":   nil,
+		"nothing": nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatal("ParseYAML:", got)
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	for _, c := range []struct{ doc, want string }{
+		{"a: 1\n  b: 2", "line 2: bad indentation"},
+		{"a: 1\na: 2", `line 2: duplicate key "a"`},
+		{"a\n", "line 1: a key: value pair is expected"},
+		{"a: |\n  text", "line 1: unsupported syntax"},
+		{"a:\n- x: 1", "line 2: mappings in sequences are not supported"},
+		{"- a\n- b", "line 1: a mapping is expected at top level"},
+		{"a: \"x", "line 1: bad double-quoted string"},
+	} {
+		if _, err := ParseYAML([]byte(c.doc)); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Fatal("ParseYAML:", c.doc, err)
+		}
+	}
+}
+
+func TestParseTOML(t *testing.T) {
+	const doc = `# server
+addr = ":8080" # listen address
+debug = true
+timeout = 1_000
+started = 1979-05-27 07:32:00Z
+
+[db]
+url = 'mysql://root@/blog'
+hosts = ["a.local", "b.local # not a comment", ]
+"max.conns" = 10
+
+[log.file]
+path = "/var/log/app.log"
+`
+	got, err := ParseTOML([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"addr":    ":8080",
+		"debug":   "true",
+		"timeout": "1000",
+		"started": "1979-05-27 07:32:00Z",
+		"db": map[string]any{
+			"url":       "mysql://root@/blog",
+			"hosts":     []any{"a.local", "b.local # not a comment"},
+			"max.conns": "10",
+		},
+		"log": map[string]any{
+			"file": map[string]any{"path": "/var/log/app.log"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatal("ParseTOML:", got)
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	for _, c := range []struct{ doc, want string }{
+		{"a = 1\na = 2", `line 2: duplicate key "a"`},
+		{"a", "line 1: a key = value pair is expected"},
+		{"[[servers]]", "line 1: arrays of tables are not supported"},
+		{"a = 1\n[a]", `line 2: key "a" is not a table`},
+		{"a = {x = 1}", "line 1: inline tables are not supported"},
+		{"a = [1,\n2]", "line 1: bad array"},
+		{"a b = 1", `line 1: bad bare key "a b"`},
+	} {
+		if _, err := ParseTOML([]byte(c.doc)); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Fatal("ParseTOML:", c.doc, err)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+
+// ParseTOML parses a TOML document of the subset used by config files:
+// tables (eg. [db] or [a.b]), key/value pairs with bare, quoted or dotted
+// keys, and single-line values (strings, numbers, booleans, dates and arrays).
+// Values other than strings and arrays are returned in their literal forms.
+func ParseTOML(data []byte) (map[string]any, error) {
+	ret := make(map[string]any)
+	table := ret
+	for i, line := range strings.Split(string(data), "\n") {
+		no := i + 1
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" {
+			continue
+		}
+		if line[0] == '[' {
+			if strings.HasPrefix(line, "[[") {
+				return nil, tomlError(no, "arrays of tables are not supported")
+			}
+			if line[len(line)-1] != ']' {
+				return nil, tomlError(no, "bad table header")
+			}
+			keys, err := tomlKeys(no, line[1:len(line)-1])
+			if err != nil {
+				return nil, err
+			}
+			if table, err = tomlTable(no, ret, keys); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		k, v, ok := cutAssign(line)
+		if !ok {
+			return nil, tomlError(no, "a key = value pair is expected")
+		}
+		keys, err := tomlKeys(no, k)
+		if err != nil {
+			return nil, err
+		}
+		t, err := tomlTable(no, table, keys[:len(keys)-1])
+		if err != nil {
+			return nil, err
+		}
+		key := keys[len(keys)-1]
+		if _, ok := t[key]; ok {
+			return nil, tomlError(no, "duplicate key "+strconv.Quote(key))
+		}
+		if t[key], err = tomlValue(no, v); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// cutAssign splits "key = value" by the first "=" out of quoted keys.
+func cutAssign(line string) (key, val string, ok bool) {
+	var q byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case q != 0:
+			if c == q {
+				q = 0
+			}
+		case c == '"' || c == '\'':
+			q = c
+		case c == '=':
+			return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]), true
+		}
+	}
+	return
+}
+
+// tomlKeys splits a dotted key, eg. a."b.c".d => [a b.c d].
+func tomlKeys(no int, s string) ([]string, error) {
+	var keys []string
+	for _, part := range splitOutOfQuotes(s, '.') {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+			return nil, tomlError(no, "empty key")
+		case part[0] == '"':
+			k, err := strconv.Unquote(part)
+			if err != nil {
+				return nil, tomlError(no, "bad quoted key")
+			}
+			part = k
+		case part[0] == '\'':
+			if len(part) < 2 || part[len(part)-1] != '\'' {
+				return nil, tomlError(no, "bad quoted key")
+			}
+			part = part[1 : len(part)-1]
+		default:
+			for _, c := range part {
+				if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-') {
+					return nil, tomlError(no, "bad bare key "+strconv.Quote(part))
+				}
+			}
+		}
+		keys = append(keys, part)
+	}
+	return keys, nil
+}
+
+// tomlTable returns the table of keys in root, creating it if not exists.
+func tomlTable(no int, root map[string]any, keys []string) (map[string]any, error) {
+	t := root
+	for _, key := range keys {
+		switch v := t[key].(type) {
+		case nil:
+			sub := make(map[string]any)
+			t[key], t = sub, sub
+		case map[string]any:
+			t = v
+		default:
+			return nil, tomlError(no, "key "+strconv.Quote(key)+" is not a table")
+		}
+	}
+	return t, nil
+}
+
+func tomlValue(no int, s string) (any, error) {
+	switch {
+	case s == "":
+		return nil, tomlError(no, "empty value")
+	case strings.HasPrefix(s, `"""`) || strings.HasPrefix(s, "'''"):
+		return nil, tomlError(no, "multi-line strings are not supported")
+	case s[0] == '"':
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, tomlError(no, "bad string")
+		}
+		return v, nil
+	case s[0] == '\'':
+		if len(s) < 2 || s[len(s)-1] != '\'' || strings.Contains(s[1:len(s)-1], "'") {
+			return nil, tomlError(no, "bad literal string")
+		}
+		return s[1 : len(s)-1], nil
+	case s[0] == '[':
+		if s[len(s)-1] != ']' {
+			return nil, tomlError(no, "bad array (multi-line arrays are not supported)")
+		}
+		ret := []any{}
+		for _, item := range splitOutOfQuotes(s[1:len(s)-1], ',') {
+			if item = strings.TrimSpace(item); item == "" { // trailing comma
+				continue
+			}
+			v, err := tomlValue(no, item)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, v)
+		}
+		return ret, nil
+	case s[0] == '{':
+		return nil, tomlError(no, "inline tables are not supported")
+	}
+	if strings.ContainsAny(s, " \t") && !isDateTime(s) {
+		return nil, tomlError(no, "bad value "+strconv.Quote(s))
+	}
+	return strings.ReplaceAll(s, "_", ""), nil
+}
+
+// isDateTime reports whether s is like "1979-05-27 07:32:00Z".
+func isDateTime(s string) bool {
+	date, time, ok := strings.Cut(s, " ")
+	return ok && len(date) == 10 && date[4] == '-' && strings.Contains(time, ":")
+}
+
+// splitOutOfQuotes splits s by sep out of quoted strings and brackets.
+func splitOutOfQuotes(s string, sep byte) (items []string) {
+	var q byte
+	depth, from := 0, 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case q != 0:
+			if q == '"' && c == '\\' {
+				i++
+			} else if c == q {
+				q = 0
+			}
+		case c == '"' || c == '\'':
+			q = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == sep && depth == 0:
+			items = append(items, s[from:i])
+			from = i + 1
+		}
+	}
+	return append(items, s[from:])
+}
+
+func tomlError(no int, msg string) error {
+	return fmt.Errorf("toml: line %d: %s", no, msg)
+}
+
+// -----------------------------------------------------------------------------
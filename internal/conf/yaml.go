@@ -0,0 +1,278 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package conf parses config files into trees of map[string]any, []any and
+// scalars (string, or nil for YAML null).
+package conf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+
+type yamlLine struct {
+	no     int // line number
+	indent int
+	text   string
+}
+
+// ParseYAML parses a YAML document of the subset used by config files:
+// block mappings and sequences, flow sequences of scalars (eg. [a, b]), and
+// plain, single-quoted or double-quoted scalars.
+func ParseYAML(data []byte) (map[string]any, error) {
+	var lines []yamlLine
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(stripComment(line), " \t\r")
+		text := strings.TrimLeft(line, " ")
+		if text == "" || text == "---" {
+			continue
+		}
+		if strings.HasPrefix(text, "\t") {
+			return nil, yamlError(i+1, "tabs are not allowed for indentation")
+		}
+		lines = append(lines, yamlLine{no: i + 1, indent: len(line) - len(text), text: text})
+	}
+	if len(lines) == 0 {
+		return map[string]any{}, nil
+	}
+	p := &yamlParser{lines: lines}
+	v, err := p.parseBlock(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(lines) {
+		return nil, yamlError(lines[p.pos].no, "bad indentation")
+	}
+	ret, ok := v.(map[string]any)
+	if !ok {
+		return nil, yamlError(lines[0].no, "a mapping is expected at top level")
+	}
+	return ret, nil
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func (p *yamlParser) parseBlock(indent int) (any, error) {
+	if strings.HasPrefix(p.lines[p.pos].text, "- ") || p.lines[p.pos].text == "-" {
+		return p.parseSeq(indent)
+	}
+	return p.parseMap(indent)
+}
+
+func (p *yamlParser) parseSeq(indent int) (any, error) {
+	var ret []any
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, yamlError(line.no, "bad indentation")
+		}
+		if line.text != "-" && !strings.HasPrefix(line.text, "- ") {
+			break // eg. the next key of a sequence at the same indentation
+		}
+		p.pos++
+		item := strings.TrimSpace(line.text[1:])
+		if item == "" {
+			v, err := p.parseNested(line, indent)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, v)
+			continue
+		}
+		if _, _, ok := cutKey(item); ok {
+			return nil, yamlError(line.no, "mappings in sequences are not supported")
+		}
+		v, err := yamlScalar(line.no, item)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, v)
+	}
+	return ret, nil
+}
+
+func (p *yamlParser) parseMap(indent int) (any, error) {
+	ret := make(map[string]any)
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, yamlError(line.no, "bad indentation")
+		}
+		key, val, ok := cutKey(line.text)
+		if !ok {
+			return nil, yamlError(line.no, "a key: value pair is expected")
+		}
+		if k, err := yamlScalar(line.no, key); err != nil {
+			return nil, err
+		} else if key, ok = k.(string); !ok {
+			return nil, yamlError(line.no, "bad key")
+		}
+		if _, ok := ret[key]; ok {
+			return nil, yamlError(line.no, "duplicate key "+strconv.Quote(key))
+		}
+		p.pos++
+		var v any
+		var err error
+		if val == "" {
+			v, err = p.parseNested(line, indent)
+		} else {
+			v, err = yamlScalar(line.no, val)
+		}
+		if err != nil {
+			return nil, err
+		}
+		ret[key] = v
+	}
+	return ret, nil
+}
+
+// parseNested parses the block nested in line, eg. values of "key:". A
+// sequence can be at the same indentation as its key.
+func (p *yamlParser) parseNested(line yamlLine, indent int) (any, error) {
+	if p.pos < len(p.lines) {
+		next := p.lines[p.pos]
+		if next.indent > indent || next.indent == indent && strings.HasPrefix(next.text, "- ") && !strings.HasPrefix(line.text, "-") {
+			return p.parseBlock(next.indent)
+		}
+	}
+	return nil, nil
+}
+
+// cutKey splits "key: value" of a mapping entry.
+func cutKey(text string) (key, val string, ok bool) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := quoteEnd(text)
+		if end < 0 {
+			return
+		}
+		rest := text[end+1:]
+		if rest != ":" && !strings.HasPrefix(rest, ": ") {
+			return
+		}
+		return text[:end+1], strings.TrimSpace(rest[1:]), true
+	}
+	if i := strings.Index(text, ": "); i >= 0 {
+		return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+2:]), true
+	}
+	if strings.HasSuffix(text, ":") {
+		return strings.TrimSpace(text[:len(text)-1]), "", true
+	}
+	return
+}
+
+// quoteEnd returns the index of the closing quote of a quoted string.
+func quoteEnd(s string) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case s[i] == q:
+			if q == '\'' && i+1 < len(s) && s[i+1] == '\'' { // '' => '
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// stripComment removes a comment (of YAML or TOML) which starts with " #" or
+// "#" at the start of a line, and isn't in a quoted string.
+func stripComment(line string) string {
+	var q byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case q != 0:
+			if q == '"' && c == '\\' {
+				i++
+			} else if c == q {
+				q = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" \t:[,-=", rune(line[i-1])) {
+				q = c
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func yamlScalar(no int, s string) (any, error) {
+	switch {
+	case s[0] == '"':
+		if quoteEnd(s) != len(s)-1 {
+			return nil, yamlError(no, "bad double-quoted string")
+		}
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, yamlError(no, "bad double-quoted string")
+		}
+		return v, nil
+	case s[0] == '\'':
+		if quoteEnd(s) != len(s)-1 {
+			return nil, yamlError(no, "bad single-quoted string")
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case s[0] == '[':
+		if s[len(s)-1] != ']' {
+			return nil, yamlError(no, "bad flow sequence")
+		}
+		ret := []any{}
+		if inner := strings.TrimSpace(s[1 : len(s)-1]); inner != "" {
+			for _, item := range splitOutOfQuotes(inner, ',') {
+				if item = strings.TrimSpace(item); item == "" {
+					return nil, yamlError(no, "empty item in flow sequence")
+				}
+				v, err := yamlScalar(no, item)
+				if err != nil {
+					return nil, err
+				}
+				ret = append(ret, v)
+			}
+		}
+		return ret, nil
+	case s == "{}":
+		return map[string]any{}, nil
+	case s[0] == '{' || s[0] == '|' || s[0] == '>' || s[0] == '&' || s[0] == '*' || s[0] == '!':
+		return nil, yamlError(no, "unsupported syntax "+strconv.Quote(s))
+	case s == "~" || s == "null" || s == "Null" || s == "NULL":
+		return nil, nil
+	}
+	return s, nil
+}
+
+func yamlError(no int, msg string) error {
+	return fmt.Errorf("yaml: line %d: %s", no, msg)
+}
+
+// -----------------------------------------------------------------------------
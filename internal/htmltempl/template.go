@@ -35,15 +35,15 @@ type Template struct {
 	*template.Template
 }
 
-func (p *Template) InitTemplates(fsys fs.FS, delimLeft, delimRight, suffix string) {
-	tpl, err := parseFS(fsys, delimLeft, delimRight, suffix)
+func (p *Template) InitTemplates(fsys fs.FS, delimLeft, delimRight, suffix string, funcs template.FuncMap) {
+	tpl, err := parseFS(fsys, delimLeft, delimRight, suffix, funcs)
 	if err != nil {
 		log.Panicln(err)
 	}
 	p.Template = tpl
 }
 
-func parseFS(fsys fs.FS, delimLeft, delimRight, suffix string) (*template.Template, error) {
+func parseFS(fsys fs.FS, delimLeft, delimRight, suffix string, funcs template.FuncMap) (*template.Template, error) {
 	if delimLeft == "" {
 		delimLeft = "{{"
 	}
@@ -51,6 +51,9 @@ func parseFS(fsys fs.FS, delimLeft, delimRight, suffix string) (*template.Templa
 		delimRight = "}}"
 	}
 	t := template.New("").Delims(delimLeft, delimRight)
+	if funcs != nil {
+		t.Funcs(funcs)
+	}
 
 	pattern := "*" + suffix
 	filenames, err := fs.Glob(fsys, pattern)
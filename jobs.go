@@ -0,0 +1,345 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrJobQueueFull is returned by Enqueue if the job queue is full.
+	ErrJobQueueFull = errors.New("yap: job queue is full")
+
+	// ErrJobsStopped is returned by Enqueue after Shutdown.
+	ErrJobsStopped = errors.New("yap: jobs are stopped")
+)
+
+// -----------------------------------------------------------------------------
+
+// JobOptions represents options of a job.
+type JobOptions struct {
+	// Name is the job name shown in logs.
+	Name string
+
+	// Retries is the max number of retries of a failed run.
+	Retries int
+
+	// Backoff is the delay before the first retry (default is 1s), which
+	// doubles on each retry up to MaxBackoff (default is 1m).
+	Backoff, MaxBackoff time.Duration
+}
+
+type jobFunc = func(ctx context.Context) error
+
+type scheduledJob struct {
+	fn   jobFunc
+	opts JobOptions
+	next func(t time.Time) time.Time
+}
+
+type queuedJob struct {
+	fn   jobFunc
+	opts JobOptions
+}
+
+// jobRunner runs jobs of an engine.
+type jobRunner struct {
+	mutex     sync.Mutex
+	scheduled []*scheduledJob
+	queue     chan queuedJob
+	workers   int
+	ctx       context.Context // nil before started
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	stopped   bool
+}
+
+// Every runs fn every d in background, eg.
+//
+//	y.Every(time.Hour, func(ctx context.Context) error {
+//		return deleteExpiredSessions(ctx)
+//	})
+//
+// Jobs start when Run begins listening (see StartJobs), and are cancelled by
+// Shutdown. Runs of a job don't overlap: the next run is scheduled after the
+// previous one finishes.
+func (p *Engine) Every(d time.Duration, fn func(ctx context.Context) error, opts ...JobOptions) {
+	if d <= 0 {
+		log.Panicln("Every: non-positive interval", d)
+	}
+	p.schedule(fn, jobOpts(opts, "every "+d.String()), func(t time.Time) time.Time {
+		return t.Add(d)
+	})
+}
+
+// Cron runs fn in background at times matching a cron expression of 5 fields
+// (minute, hour, day of month, month and day of week) in local time, eg.
+// "0 3 * * *" (at 03:00 every day), "*/15 9-17 * * mon-fri" or "@hourly".
+// See Every.
+func (p *Engine) Cron(spec string, fn func(ctx context.Context) error, opts ...JobOptions) {
+	sched, err := parseCron(spec)
+	if err != nil {
+		log.Panicln("Cron:", err)
+	}
+	p.schedule(fn, jobOpts(opts, "cron "+spec), sched.next)
+}
+
+func jobOpts(opts []JobOptions, name string) JobOptions {
+	var ret JobOptions
+	if opts != nil {
+		ret = opts[0]
+	}
+	if ret.Name == "" {
+		ret.Name = name
+	}
+	if ret.Backoff <= 0 {
+		ret.Backoff = time.Second
+	}
+	if ret.MaxBackoff <= 0 {
+		ret.MaxBackoff = time.Minute
+	}
+	return ret
+}
+
+func (p *Engine) schedule(fn jobFunc, opts JobOptions, next func(t time.Time) time.Time) {
+	r := &p.jobs
+	job := &scheduledJob{fn: fn, opts: opts, next: next}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.scheduled = append(r.scheduled, job)
+	if r.ctx != nil && !r.stopped {
+		r.startScheduled(job)
+	}
+}
+
+// SetJobWorkers sets the number of workers running jobs of Enqueue (default
+// is 4), and the size of the job queue (default is 100). It should be called
+// before Enqueue.
+func (p *Engine) SetJobWorkers(workers, queueSize int) {
+	r := &p.jobs
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.queue != nil {
+		log.Panicln("SetJobWorkers: the job queue is in use")
+	}
+	r.workers, r.queue = workers, make(chan queuedJob, queueSize)
+}
+
+// Enqueue adds fn to the job queue, which is run by a worker in background,
+// eg. to send an email out of a request:
+//
+//	err := ctx.Enqueue(func(ctx context.Context) error {
+//		return sendWelcomeEmail(ctx, user)
+//	}, yap.JobOptions{Retries: 3})
+//
+// It returns ErrJobQueueFull if the queue is full, or ErrJobsStopped after
+// Shutdown. Jobs enqueued before StartJobs run after it, and jobs still
+// queued when Shutdown is called are dropped.
+func (p *Engine) Enqueue(fn func(ctx context.Context) error, opts ...JobOptions) error {
+	r := &p.jobs
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.stopped {
+		return ErrJobsStopped
+	}
+	if r.queue == nil {
+		r.workers, r.queue = 4, make(chan queuedJob, 100)
+		if r.ctx != nil {
+			r.startWorkers()
+		}
+	}
+	select {
+	case r.queue <- queuedJob{fn: fn, opts: jobOpts(opts, "queued job")}:
+		return nil
+	default:
+		return ErrJobQueueFull
+	}
+}
+
+// Enqueue adds fn to the job queue of the engine. See Engine.Enqueue.
+func (p *Context) Enqueue(fn func(ctx context.Context) error, opts ...JobOptions) error {
+	return p.engine.Enqueue(fn, opts...)
+}
+
+// StartJobs starts jobs (see Every, Cron and Enqueue). Run calls it when it
+// begins listening, so it is only needed when the engine serves requests in
+// other ways, eg. by Handler or a listenAndServe func set by SetLAS.
+func (p *Engine) StartJobs() {
+	r := &p.jobs
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.ctx != nil || r.stopped {
+		return
+	}
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	for _, job := range r.scheduled {
+		r.startScheduled(job)
+	}
+	if r.queue != nil {
+		r.startWorkers()
+	}
+}
+
+func (r *jobRunner) startScheduled(job *scheduledJob) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for {
+			next := job.next(time.Now())
+			if next.IsZero() {
+				return
+			}
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-r.ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			runJob(r.ctx, job.fn, job.opts)
+		}
+	}()
+}
+
+func (r *jobRunner) startWorkers() {
+	for i := 0; i < r.workers; i++ {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			for {
+				select {
+				case <-r.ctx.Done():
+					return
+				case job := <-r.queue:
+					runJob(r.ctx, job.fn, job.opts)
+				}
+			}
+		}()
+	}
+}
+
+// stop cancels jobs, and waits for running jobs until ctx is done.
+func (r *jobRunner) stop(ctx context.Context) error {
+	r.mutex.Lock()
+	r.stopped = true
+	cancel := r.cancel
+	r.mutex.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runJob runs fn, and retries with exponential backoff if it fails.
+func runJob(ctx context.Context, fn jobFunc, opts JobOptions) {
+	backoff := opts.Backoff
+	for retry := 0; ; retry++ {
+		err := callJob(ctx, fn)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		if retry >= opts.Retries {
+			log.Println("yap: job", opts.Name, "failed:", err)
+			return
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > opts.MaxBackoff {
+			backoff = opts.MaxBackoff
+		}
+	}
+}
+
+func callJob(ctx context.Context, fn jobFunc) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+	}()
+	return fn(ctx)
+}
+
+// -----------------------------------------------------------------------------
+
+// protoJob returns a job which runs a YAP handler with a Context of a fake
+// request. The job fails if the handler replies an error status (eg. by
+// `reply err`).
+func (p *Engine) protoJob(proto HandlerProto) jobFunc {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, "JOB", "/", nil)
+		if err != nil {
+			return err
+		}
+		w := &jobWriter{header: make(http.Header)}
+		runProto(p.NewContext(w, req), proto)
+		if w.code >= 400 {
+			return fmt.Errorf("%d %s", w.code, strings.TrimSpace(w.body.String()))
+		}
+		return nil
+	}
+}
+
+// jobWriter records the status code and the head of the body replied by a
+// job handler.
+type jobWriter struct {
+	header http.Header
+	code   int
+	body   strings.Builder
+}
+
+func (w *jobWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *jobWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *jobWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if n := 256 - w.body.Len(); n > 0 {
+		w.body.Write(b[:min(n, len(b))])
+	}
+	return len(b), nil
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goplus/yap"
+)
+
+func TestJobsLifecycle(t *testing.T) {
+	e := yap.New()
+	ticks := make(chan struct{}, 1)
+	stopped := make(chan struct{})
+	e.Every(5*time.Millisecond, func(ctx context.Context) error {
+		select {
+		case ticks <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		close(stopped)
+		return ctx.Err()
+	})
+	done := make(chan error)
+	go func() {
+		done <- e.Run("127.0.0.1:0")
+	}()
+	select {
+	case <-ticks:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Every: job not run")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.Shutdown(ctx); err != nil {
+		t.Fatal("Shutdown:", err)
+	}
+	<-stopped
+	if err := <-done; err != nil {
+		t.Fatal("Run:", err)
+	}
+	if err := e.Enqueue(func(ctx context.Context) error { return nil }); err != yap.ErrJobsStopped {
+		t.Fatal("Enqueue:", err)
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	e := yap.New()
+	e.SetJobWorkers(1, 1)
+	var calls atomic.Int32
+	done := make(chan struct{})
+	err := e.Enqueue(func(ctx context.Context) error {
+		if calls.Add(1) < 3 {
+			return errors.New("temporary error")
+		}
+		close(done)
+		return nil
+	}, yap.JobOptions{Retries: 2, Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatal("Enqueue:", err)
+	}
+	if err = e.Enqueue(func(ctx context.Context) error { return nil }); err != yap.ErrJobQueueFull {
+		t.Fatal("Enqueue:", err)
+	}
+	e.StartJobs()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Enqueue: job not done")
+	}
+	if calls.Load() != 3 {
+		t.Fatal("calls:", calls.Load())
+	}
+	e.Shutdown(context.Background())
+}
+
+func TestCronErrors(t *testing.T) {
+	e := yap.New()
+	for _, spec := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "0 0 * jam *"} {
+		func() {
+			defer func() {
+				if e := recover(); e == nil || !strings.Contains(e.(string), "cron:") {
+					t.Fatal("Cron:", spec, e)
+				}
+			}()
+			e.Cron(spec, func(ctx context.Context) error { return nil })
+		}()
+	}
+	e.Cron("*/15 9-17 * * mon-fri", func(ctx context.Context) error { return nil })
+	e.Cron("@daily", func(ctx context.Context) error { return nil })
+}
+
+type JobAppV2 struct {
+	yap.AppV2
+	runs atomic.Int32
+	done chan struct{}
+}
+
+func (p *JobAppV2) Main() {
+	yap.XGot_AppV2_Main(p,
+		&grammarHandler{fname: "get"}, &jobHandler{fname: "every_5ms@tick"}, &jobHandler{fname: "cron_0_3_*_*_*"},
+	)
+}
+
+type jobHandler struct {
+	yap.Handler
+	*JobAppV2
+	fname string
+}
+
+func (p *jobHandler) Main(ctx *yap.Context) {
+	p.Handler.Main(ctx)
+	if p.runs.Add(1) == 1 {
+		ctx.Reply__1(errors.New("first run fails"))
+		return
+	}
+	close(p.done)
+}
+
+func (p *jobHandler) Classfname() string {
+	return p.fname
+}
+
+func (p *jobHandler) Classclone() yap.HandlerProto {
+	ret := *p
+	return &ret
+}
+
+func TestClassfileJobs(t *testing.T) {
+	app := &JobAppV2{done: make(chan struct{})}
+	app.InitYap()
+	app.SetLAS(func(addr string, h http.Handler) error { return nil })
+	app.Main()
+	if routes := app.Routes(); len(routes) != 1 {
+		t.Fatal("Routes:", routes)
+	}
+	app.StartJobs()
+	defer app.Shutdown(context.Background())
+	select {
+	case <-app.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("every_5ms: job not run")
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goplus/yap/metrics"
+)
+
+// MetricsOptions represents options of UseMetrics.
+type MetricsOptions struct {
+	// Path is the path to expose metrics. It is "/metrics" by default.
+	Path string
+
+	// Registry is the registry to collect and expose metrics. It is
+	// metrics.Default by default.
+	Registry *metrics.Registry
+}
+
+type engineMetrics struct {
+	reg      *metrics.Registry
+	requests *metrics.Counter
+	duration *metrics.Histogram
+	inFlight *metrics.Gauge
+	render   *metrics.Histogram
+}
+
+// UseMetrics collects metrics of HTTP requests and template rendering, and
+// exposes them (and other metrics in the registry, eg. ydb query timing) in
+// the Prometheus text format. The registry is put in contexts of requests
+// (see metrics.NewContext), so packages collect their metrics of requests in
+// it. Metrics collected are:
+//   - yap_http_requests_total{method,route,status}
+//   - yap_http_request_duration_seconds{method,route,status}
+//   - yap_http_requests_in_flight
+//   - yap_template_render_duration_seconds{template}
+//
+// The route label is the route pattern, eg. "/p/:id", or "" if no route is
+// matched. The method label is "OTHER" for non-standard methods.
+func (p *Engine) UseMetrics(opts ...MetricsOptions) {
+	var o MetricsOptions
+	if opts != nil {
+		o = opts[0]
+	}
+	if o.Path == "" {
+		o.Path = "/metrics"
+	}
+	reg := o.Registry
+	if reg == nil {
+		reg = metrics.Default
+	}
+	m := &engineMetrics{
+		reg: reg,
+		requests: reg.Counter("yap_http_requests_total",
+			"Total number of HTTP requests.", "method", "route", "status"),
+		duration: reg.Histogram("yap_http_request_duration_seconds",
+			"HTTP request latencies in seconds.", nil, "method", "route", "status"),
+		inFlight: reg.Gauge("yap_http_requests_in_flight",
+			"Number of HTTP requests being served."),
+		render: reg.Histogram("yap_template_render_duration_seconds",
+			"YAP template rendering time in seconds.", nil, "template"),
+	}
+	p.metrics = m
+	p.Use(m.middleware)
+	p.router.GET(o.Path, func(ctx *Context) {
+		reg.ServeHTTP(ctx.ResponseWriter, ctx.Request)
+	})
+}
+
+func (m *engineMetrics) middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+		rw := wrapResponse(w)
+		r, route := routeRecorder(r)
+		r = r.WithContext(metrics.NewContext(r.Context(), m.reg))
+		h.ServeHTTP(rw, r)
+		method, status := methodLabel(r.Method), strconv.Itoa(rw.Status())
+		m.requests.Inc(method, *route, status)
+		m.duration.Since(start, method, *route, status)
+	})
+}
+
+// methodLabel returns the method label of a request. Methods other than the
+// standard ones are "OTHER", so clients can't add series without bound.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "OTHER"
+}
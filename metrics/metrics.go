@@ -0,0 +1,351 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package metrics implements counters, gauges and histograms which are
+// exposed in the Prometheus text format.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefBuckets are the default histogram buckets, in seconds.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Default is the default registry.
+var Default = NewRegistry()
+
+// -----------------------------------------------------------------------------
+
+// Registry is a set of metrics.
+type Registry struct {
+	metrics map[string]*metric
+	mutex   sync.Mutex
+}
+
+// NewRegistry creates a new Registry.
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]*metric)}
+}
+
+// Counter registers a counter (or returns the registered one with the same
+// name). labels are names of its labels.
+func (p *Registry) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{p.register("counter", name, help, labels, nil)}
+}
+
+// Gauge registers a gauge (or returns the registered one with the same name).
+func (p *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{p.register("gauge", name, help, labels, nil)}
+}
+
+// Histogram registers a histogram (or returns the registered one with the
+// same name). If buckets is nil, DefBuckets is used.
+func (p *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	return &Histogram{p.register("histogram", name, help, labels, buckets)}
+}
+
+func (p *Registry) register(typ, name, help string, labels []string, buckets []float64) *metric {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if m, ok := p.metrics[name]; ok {
+		if m.typ != typ || !slices.Equal(m.labels, labels) {
+			log.Panicln("metrics: conflicting registration of", name)
+		}
+		return m
+	}
+	m := &metric{
+		typ: typ, name: name, help: help, labels: labels, buckets: buckets,
+		series: make(map[string]*series),
+	}
+	if len(labels) == 0 {
+		m.get(nil) // exported as 0 from the start
+	}
+	p.metrics[name] = m
+	return m
+}
+
+// WriteText writes all metrics in the Prometheus text format.
+func (p *Registry) WriteText(w io.Writer) error {
+	p.mutex.Lock()
+	names := make([]string, 0, len(p.metrics))
+	for name := range p.metrics {
+		names = append(names, name)
+	}
+	p.mutex.Unlock()
+	sort.Strings(names)
+
+	b := bufio.NewWriter(w)
+	for _, name := range names {
+		p.mutex.Lock()
+		m := p.metrics[name]
+		p.mutex.Unlock()
+		m.writeText(b)
+	}
+	return b.Flush()
+}
+
+// ServeHTTP serves metrics in the Prometheus text format.
+func (p *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteText(w)
+}
+
+// -----------------------------------------------------------------------------
+
+type series struct {
+	labels string // formatted label pairs
+	value  float64
+	counts []uint64 // histogram bucket counts (not cumulative)
+	count  uint64   // histogram count
+}
+
+type metric struct {
+	typ, name, help string
+	labels          []string
+	buckets         []float64
+	series          map[string]*series
+	mutex           sync.Mutex
+}
+
+// get returns the series of labelValues. It must be called with m.mutex held.
+func (m *metric) get(labelValues []string) *series {
+	if len(labelValues) != len(m.labels) {
+		log.Panicln("metrics:", m.name, "expects labels", m.labels)
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := m.series[key]
+	if !ok {
+		var b strings.Builder
+		for i, label := range m.labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(label)
+			b.WriteString(`="`)
+			b.WriteString(escapeLabel(labelValues[i]))
+			b.WriteByte('"')
+		}
+		s = &series{labels: b.String()}
+		if m.buckets != nil {
+			s.counts = make([]uint64, len(m.buckets))
+		}
+		m.series[key] = s
+	}
+	return s
+}
+
+func (m *metric) add(v float64, labelValues []string) {
+	m.mutex.Lock()
+	m.get(labelValues).value += v
+	m.mutex.Unlock()
+}
+
+func (m *metric) set(v float64, labelValues []string) {
+	m.mutex.Lock()
+	m.get(labelValues).value = v
+	m.mutex.Unlock()
+}
+
+func (m *metric) writeText(b *bufio.Writer) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.help != "" {
+		b.WriteString("# HELP " + m.name + " " + escapeHelp(m.help) + "\n")
+	}
+	b.WriteString("# TYPE " + m.name + " " + m.typ + "\n")
+	keys := make([]string, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := m.series[key]
+		if m.typ != "histogram" {
+			writeSample(b, m.name, s.labels, "", s.value)
+			continue
+		}
+		var cum uint64
+		for i, le := range m.buckets {
+			cum += s.counts[i]
+			writeSample(b, m.name+"_bucket", s.labels, `le="`+formatFloat(le)+`"`, float64(cum))
+		}
+		writeSample(b, m.name+"_bucket", s.labels, `le="+Inf"`, float64(s.count))
+		writeSample(b, m.name+"_sum", s.labels, "", s.value)
+		writeSample(b, m.name+"_count", s.labels, "", float64(s.count))
+	}
+}
+
+func writeSample(b *bufio.Writer, name, labels, extra string, v float64) {
+	b.WriteString(name)
+	if labels != "" || extra != "" {
+		b.WriteByte('{')
+		b.WriteString(labels)
+		if labels != "" && extra != "" {
+			b.WriteByte(',')
+		}
+		b.WriteString(extra)
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatFloat(v))
+	b.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+// -----------------------------------------------------------------------------
+
+// Counter is a metric whose value only goes up.
+type Counter struct {
+	m *metric
+}
+
+// Inc increments the counter of labelValues by 1.
+func (p *Counter) Inc(labelValues ...string) {
+	p.m.add(1, labelValues)
+}
+
+// Add adds v (v >= 0) to the counter of labelValues.
+func (p *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		log.Panicln("metrics: counter", p.m.name, "can't decrease")
+	}
+	p.m.add(v, labelValues)
+}
+
+// Gauge is a metric whose value can go up and down.
+type Gauge struct {
+	m *metric
+}
+
+// Set sets the gauge of labelValues to v.
+func (p *Gauge) Set(v float64, labelValues ...string) {
+	p.m.set(v, labelValues)
+}
+
+// Add adds v to the gauge of labelValues.
+func (p *Gauge) Add(v float64, labelValues ...string) {
+	p.m.add(v, labelValues)
+}
+
+// Inc increments the gauge of labelValues by 1.
+func (p *Gauge) Inc(labelValues ...string) {
+	p.m.add(1, labelValues)
+}
+
+// Dec decrements the gauge of labelValues by 1.
+func (p *Gauge) Dec(labelValues ...string) {
+	p.m.add(-1, labelValues)
+}
+
+// Histogram samples observations and counts them in buckets.
+type Histogram struct {
+	m *metric
+}
+
+// Observe adds an observation v to the histogram of labelValues.
+func (p *Histogram) Observe(v float64, labelValues ...string) {
+	m := p.m
+	m.mutex.Lock()
+	s := m.get(labelValues)
+	if i, _ := slices.BinarySearch(m.buckets, v); i < len(m.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.value += v
+	m.mutex.Unlock()
+}
+
+// Since observes the time elapsed since start, in seconds.
+func (p *Histogram) Since(start time.Time, labelValues ...string) {
+	p.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+// -----------------------------------------------------------------------------
+
+type registryKey struct{}
+
+// NewContext returns a copy of ctx carrying reg. yap.Engine.UseMetrics puts
+// its registry in contexts of requests, so packages (eg. ydb) collect their
+// metrics of a request in the registry of the engine.
+func NewContext(ctx context.Context, reg *Registry) context.Context {
+	return context.WithValue(ctx, registryKey{}, reg)
+}
+
+// FromContext returns the registry carried by ctx, or nil if there is none.
+func FromContext(ctx context.Context) *Registry {
+	reg, _ := ctx.Value(registryKey{}).(*Registry)
+	return reg
+}
+
+// -----------------------------------------------------------------------------
+
+// NewCounter registers a counter to the default registry.
+func NewCounter(name, help string, labels ...string) *Counter {
+	return Default.Counter(name, help, labels...)
+}
+
+// NewGauge registers a gauge to the default registry.
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return Default.Gauge(name, help, labels...)
+}
+
+// NewHistogram registers a histogram to the default registry.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return Default.Histogram(name, help, buckets, labels...)
+}
+
+// -----------------------------------------------------------------------------
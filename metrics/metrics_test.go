@@ -0,0 +1,91 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	reg := NewRegistry()
+	c := reg.Counter("jobs_total", "Total jobs.\nDone.", "kind")
+	c.Inc("email")
+	c.Add(2, `a"b\`)
+	if reg.Counter("jobs_total", "", "kind") == nil {
+		t.Fatal("Counter: re-register")
+	}
+	g := reg.Gauge("queue_size", "")
+	g.Set(3)
+	g.Dec()
+	h := reg.Histogram("latency_seconds", "Latency.", []float64{1, 0.1})
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(2)
+
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	const want = `# HELP jobs_total Total jobs.\nDone.
+# TYPE jobs_total counter
+jobs_total{kind="a\"b\\"} 2
+jobs_total{kind="email"} 1
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 1
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 2.55
+latency_seconds_count 3
+# TYPE queue_size gauge
+queue_size 2
+`
+	if got := b.String(); got != want {
+		t.Fatalf("WriteText:\n%s", got)
+	}
+}
+
+func TestMisuse(t *testing.T) {
+	reg := NewRegistry()
+	c := reg.Counter("x_total", "", "a")
+	for name, fn := range map[string]func(){
+		"labels":   func() { c.Inc() },
+		"decrease": func() { c.Add(-1, "a") },
+		"conflict": func() { reg.Gauge("x_total", "") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal(name, "should panic")
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestUnlabeled(t *testing.T) {
+	reg := NewRegistry()
+	reg.Gauge("in_flight", "")
+	reg.Counter("errors_total", "", "op")
+	var b strings.Builder
+	reg.WriteText(&b)
+	if got := b.String(); !strings.Contains(got, "\nin_flight 0\n") || strings.Contains(got, "errors_total{") {
+		t.Fatalf("WriteText:\n%s", got)
+	}
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/goplus/yap"
+	"github.com/goplus/yap/metrics"
+)
+
+func TestUseMetrics(t *testing.T) {
+	reg := metrics.NewRegistry()
+	e := yap.New(fstest.MapFS{
+		"page_yap.html": {Data: []byte(`<p>{{.}}</p>`)},
+	})
+	e.UseMetrics(yap.MetricsOptions{Path: "/_metrics", Registry: reg})
+	var b strings.Builder
+	if reg.WriteText(&b); !strings.Contains(b.String(), "\nyap_http_requests_in_flight 0\n") {
+		t.Fatalf("in-flight gauge not exported:\n%s", b.String())
+	}
+	e.GET("/p/:id", func(ctx *yap.Context) {
+		ctx.YAP(200, "page", ctx.Param("id"))
+	})
+	signups := reg.Counter("app_signups_total", "Total signups.")
+	signups.Inc()
+
+	serve(e, "GET", "/p/1")
+	serve(e, "GET", "/p/2")
+	serve(e, "GET", "/nothing")
+	serve(e, "FOO1", "/nothing")
+	serve(e, "FOO2", "/nothing")
+	w := serve(e, "GET", "/_metrics")
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Fatal("Content-Type:", ct)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		`app_signups_total 1`,
+		`yap_http_requests_total{method="GET",route="/p/:id",status="200"} 2`,
+		`yap_http_requests_total{method="GET",route="",status="404"} 1`,
+		`yap_http_requests_total{method="OTHER",route="",status="404"} 2`,
+		`yap_http_request_duration_seconds_count{method="GET",route="/p/:id",status="200"} 2`,
+		`yap_http_requests_in_flight 1`,
+		`yap_template_render_duration_seconds_count{template="page"} 2`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("metrics: %s not found\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// ProxyOptions represents options of Proxy.
+type ProxyOptions struct {
+	// Rewrite is the upstream path with params captured by the route pattern,
+	// eg. "/v1/users/:id" or "/assets/*path". The request path is forwarded
+	// as is by default.
+	Rewrite string
+
+	// Weights are weights of upstreams (default is 1) for the weighted round
+	// robin balancing.
+	Weights []int
+
+	// RequestHeaders are headers set to requests to upstreams, and an empty
+	// value removes the header.
+	RequestHeaders map[string]string
+
+	// ResponseHeaders are headers set to responses from upstreams, and an
+	// empty value removes the header.
+	ResponseHeaders map[string]string
+
+	// PreserveHost forwards the Host header of the request, instead of the
+	// host of the upstream.
+	PreserveHost bool
+
+	// Timeout is the max time to wait for the response header from an
+	// upstream. It replies 504 Gateway Timeout if exceeded. Zero means no
+	// timeout.
+	Timeout time.Duration
+
+	// MaxFails is the number of consecutive failures (errors of connections
+	// or timeouts) to mark an upstream down for FailTimeout. It is 1 by
+	// default.
+	MaxFails int
+
+	// FailTimeout is how long an upstream is marked down. It is 10s by
+	// default.
+	FailTimeout time.Duration
+
+	// Transport is used to send requests to upstreams. It is
+	// http.DefaultTransport by default.
+	Transport http.RoundTripper
+}
+
+// Proxy forwards requests matching pattern (of all methods) to target, a
+// comma-separated list of upstream URLs, eg.
+//
+//	y.Proxy("/legacy/*path", "http://10.0.0.1:8080,http://10.0.0.2:8080", yap.ProxyOptions{
+//		Rewrite: "/api/*path",
+//		Timeout: 10 * time.Second,
+//	})
+//
+// Upstreams are balanced by weighted round robin, and an upstream that fails
+// is skipped for a while (see ProxyOptions.MaxFails). X-Forwarded-For,
+// X-Forwarded-Host and X-Forwarded-Proto are set, and WebSocket and
+// server-sent events pass through.
+func (p *Engine) Proxy(pattern, target string, opts ...ProxyOptions) {
+	p.Group("").Proxy(pattern, target, opts...)
+}
+
+// Proxy forwards requests matching pattern (relative to the group prefix) to
+// target. Middlewares and policies of the group apply. See Engine.Proxy.
+func (p *Group) Proxy(pattern, target string, opts ...ProxyOptions) {
+	var opt ProxyOptions
+	if opts != nil {
+		opt = opts[0]
+	}
+	pool, err := newUpstreamPool(target, opt)
+	if err != nil {
+		log.Panicln("Proxy:", err)
+	}
+	rp := &httputil.ReverseProxy{
+		Rewrite:        pool.rewrite,
+		ModifyResponse: pool.modifyResponse,
+		ErrorHandler:   pool.errorHandler,
+		Transport:      opt.Transport,
+	}
+	handle := func(ctx *Context) {
+		st := &proxyState{path: ctx.URL.Path, up: pool.pick(time.Now())}
+		if opt.Rewrite != "" {
+			st.path = rewritePath(opt.Rewrite, ctx)
+		}
+		rctx, cancel := context.WithCancel(context.WithValue(ctx.Request.Context(), proxyKey{}, st))
+		defer cancel()
+		if opt.Timeout > 0 {
+			st.timer = time.AfterFunc(opt.Timeout, func() {
+				st.timedOut.Store(true)
+				cancel()
+			})
+			defer st.timer.Stop()
+		}
+		rp.ServeHTTP(ctx.ResponseWriter, ctx.Request.WithContext(rctx))
+	}
+	for _, method := range anyMethods {
+		p.Route(method, pattern, handle)
+	}
+}
+
+// rewritePath replaces params in tpl with those captured by the route, eg.
+// "/v1/users/:id" => "/v1/users/123". It doesn't read the request body, which
+// is forwarded to the upstream.
+func rewritePath(tpl string, ctx *Context) string {
+	segs := strings.Split(tpl, "/")
+	for i, seg := range segs {
+		if seg == "" {
+			continue
+		}
+		switch seg[0] {
+		case ':':
+			segs[i] = ctx.params[seg[1:]]
+		case '*':
+			segs[i] = strings.TrimPrefix(ctx.params[seg[1:]], "/")
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+type proxyKey struct{}
+
+// proxyState is the state of a proxied request.
+type proxyState struct {
+	path     string // upstream path
+	up       *upstream
+	timer    *time.Timer // of ProxyOptions.Timeout
+	timedOut atomic.Bool
+}
+
+type upstream struct {
+	url       *url.URL
+	weight    int
+	current   int // of the smooth weighted round robin
+	fails     int
+	downUntil time.Time
+}
+
+// upstreamPool balances requests to upstreams of a proxy route.
+type upstreamPool struct {
+	mutex sync.Mutex
+	ups   []*upstream
+	opts  ProxyOptions
+}
+
+func newUpstreamPool(target string, opts ProxyOptions) (*upstreamPool, error) {
+	if opts.MaxFails <= 0 {
+		opts.MaxFails = 1
+	}
+	if opts.FailTimeout <= 0 {
+		opts.FailTimeout = 10 * time.Second
+	}
+	p := &upstreamPool{opts: opts}
+	for i, s := range strings.Split(target, ",") {
+		u, err := url.Parse(strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+			return nil, errors.New("invalid upstream " + s)
+		}
+		weight := 1
+		if i < len(opts.Weights) && opts.Weights[i] > 0 {
+			weight = opts.Weights[i]
+		}
+		p.ups = append(p.ups, &upstream{url: u, weight: weight})
+	}
+	return p, nil
+}
+
+// pick selects an upstream by the smooth weighted round robin, skipping
+// upstreams marked down unless all of them are down.
+func (p *upstreamPool) pick(now time.Time) *upstream {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	var best *upstream
+	total := 0
+	for _, skipDown := range []bool{true, false} {
+		for _, up := range p.ups {
+			if skipDown && now.Before(up.downUntil) {
+				continue
+			}
+			up.current += up.weight
+			total += up.weight
+			if best == nil || up.current > best.current {
+				best = up
+			}
+		}
+		if best != nil {
+			break
+		}
+	}
+	best.current -= total
+	return best
+}
+
+// report records a result of an upstream for passive health checks.
+func (p *upstreamPool) report(up *upstream, ok bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if ok {
+		up.fails = 0
+		return
+	}
+	if up.fails++; up.fails >= p.opts.MaxFails {
+		up.fails = 0
+		up.downUntil = time.Now().Add(p.opts.FailTimeout)
+	}
+}
+
+func (p *upstreamPool) rewrite(pr *httputil.ProxyRequest) {
+	st := pr.In.Context().Value(proxyKey{}).(*proxyState)
+	pr.Out.URL.Path, pr.Out.URL.RawPath = st.path, ""
+	pr.SetURL(st.up.url)
+	pr.SetXForwarded()
+	if p.opts.PreserveHost {
+		pr.Out.Host = pr.In.Host
+	}
+	setHeaders(pr.Out.Header, p.opts.RequestHeaders)
+}
+
+func (p *upstreamPool) modifyResponse(resp *http.Response) error {
+	st := resp.Request.Context().Value(proxyKey{}).(*proxyState)
+	if st.timer != nil {
+		st.timer.Stop() // Timeout is for the response header only
+	}
+	p.report(st.up, true)
+	setHeaders(resp.Header, p.opts.ResponseHeaders)
+	return nil
+}
+
+func (p *upstreamPool) errorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	st := r.Context().Value(proxyKey{}).(*proxyState)
+	code := http.StatusBadGateway
+	if st.timedOut.Load() {
+		code = http.StatusGatewayTimeout
+	} else if errors.Is(err, context.Canceled) { // the client is gone
+		return
+	}
+	p.report(st.up, false)
+	log.Println("yap: proxy", st.up.url.Host+st.path, "failed:", err)
+	http.Error(w, http.StatusText(code), code)
+}
+
+func setHeaders(h http.Header, values map[string]string) {
+	for k, v := range values {
+		if v == "" {
+			h.Del(k)
+		} else {
+			h.Set(k, v)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,213 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goplus/yap"
+)
+
+func upstreamServer(name string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", name)
+		w.Header().Set("X-Internal", "1")
+		fmt.Fprintf(w, "%s %s %s xff=%s xfh=%s tok=%s cookie=%s",
+			name, r.Method, r.URL.RequestURI(), r.Header.Get("X-Forwarded-For"),
+			r.Header.Get("X-Forwarded-Host"), r.Header.Get("X-Token"), r.Header.Get("Cookie"))
+	}))
+}
+
+func TestProxy(t *testing.T) {
+	up := upstreamServer("a")
+	defer up.Close()
+
+	e := yap.New()
+	e.Proxy("/legacy/users/:id/*path", up.URL+"/base", yap.ProxyOptions{
+		Rewrite:         "/v1/users/:id/*path",
+		RequestHeaders:  map[string]string{"X-Token": "secret", "Cookie": ""},
+		ResponseHeaders: map[string]string{"X-Internal": "", "X-Proxy": "yap"},
+	})
+	e.Proxy("/raw/*path", up.URL)
+
+	req := httptest.NewRequest("POST", "http://example.com/legacy/users/12/posts/3?q=go", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("Cookie", "sid=1")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	const want = "a POST /base/v1/users/12/posts/3?q=go xff=10.0.0.1 xfh=example.com tok=secret cookie="
+	if w.Code != 200 || w.Body.String() != want {
+		t.Fatal("Proxy:", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Internal") != "" || w.Header().Get("X-Proxy") != "yap" || w.Header().Get("Server") != "a" {
+		t.Fatal("Proxy: header", w.Header())
+	}
+	if w := serveWith(e, "GET", "/raw/a/b"); !strings.HasPrefix(w.Body.String(), "a GET /raw/a/b ") {
+		t.Fatal("Proxy:", w.Body.String())
+	}
+}
+
+func TestProxyFormBody(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s %s", r.URL.Path, body)
+	}))
+	defer up.Close()
+
+	e := yap.New()
+	e.Proxy("/raw/:id", up.URL)
+	e.Proxy("/api/:id", up.URL, yap.ProxyOptions{Rewrite: "/v1/:id"})
+	e.Proxy("/legacy/*path", up.URL, yap.ProxyOptions{Rewrite: "/new/*path"})
+	e.POST("/local/:id", func(ctx *yap.Context) {
+		ctx.Text__2(ctx.Param("id") + " " + ctx.Param("name") + " " + ctx.FormValue("id"))
+	})
+
+	for _, c := range []struct{ path, want string }{
+		{"/raw/7", "/raw/7 name=bob"},
+		{"/api/7", "/v1/7 name=bob"},
+		{"/legacy/a/b", "/new/a/b name=bob"},
+		{"/local/7?id=8", "7 bob 7"},
+	} {
+		req := httptest.NewRequest("POST", c.path, strings.NewReader("name=bob"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		if w.Code != 200 || w.Body.String() != c.want {
+			t.Fatal("POST", c.path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestProxyPool(t *testing.T) {
+	a, b := upstreamServer("a"), upstreamServer("b")
+	defer a.Close()
+	defer b.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	e := yap.New()
+	e.Proxy("/api/*path", a.URL+","+b.URL+","+down.URL, yap.ProxyOptions{
+		Weights:     []int{2, 1, 1},
+		FailTimeout: time.Minute,
+	})
+	var got []string
+	for i := 0; i < 7; i++ {
+		w := serveWith(e, "GET", "/api/x")
+		got = append(got, fmt.Sprint(w.Code, w.Header().Get("Server")))
+	}
+	// down fails once, and is skipped then
+	if s := strings.Join(got, ","); s != "200a,200b,502,200a,200a,200a,200b" {
+		t.Fatal("Proxy:", s)
+	}
+}
+
+func TestProxyTimeout(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(w, "data: %d\n\n", i)
+			w.(http.Flusher).Flush()
+			time.Sleep(20 * time.Millisecond)
+		}
+	}))
+	defer up.Close()
+
+	e := yap.New()
+	e.Proxy("/*path", up.URL, yap.ProxyOptions{Timeout: 50 * time.Millisecond})
+	if w := serveWith(e, "GET", "/slow"); w.Code != http.StatusGatewayTimeout {
+		t.Fatal("Proxy:", w.Code, w.Body.String())
+	}
+
+	// the timeout doesn't apply to the body, and events are flushed
+	front := httptest.NewServer(e)
+	defer front.Close()
+	resp, err := http.Get(front.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil || line != "data: 0\n" {
+		t.Fatal("SSE:", line, err)
+	}
+	if rest, err := io.ReadAll(resp.Body); err != nil || !strings.Contains(string(rest), "data: 2") {
+		t.Fatal("SSE:", string(rest), err)
+	}
+}
+
+func TestProxyUpgrade(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+		rw.Flush()
+		line, _ := rw.ReadString('\n')
+		rw.WriteString("echo " + line)
+		rw.Flush()
+	}))
+	defer up.Close()
+
+	e := yap.New()
+	e.Proxy("/ws", up.URL)
+	front := httptest.NewServer(e)
+	defer front.Close()
+
+	req, _ := http.NewRequest("GET", front.URL+"/ws", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "echo")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatal("Upgrade:", resp.Status)
+	}
+	conn := resp.Body.(io.ReadWriter)
+	io.WriteString(conn, "hello\n")
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || line != "echo hello\n" {
+		t.Fatal("Upgrade:", line, err)
+	}
+}
+
+func TestProxyErrors(t *testing.T) {
+	e := yap.New()
+	for _, target := range []string{"", "10.0.0.1:80", "http://a,ftp://b"} {
+		func() {
+			defer func() {
+				if e := recover(); e == nil {
+					t.Fatal("Proxy: no panic", target)
+				}
+			}()
+			e.Proxy("/x", target)
+		}()
+	}
+}
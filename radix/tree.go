@@ -99,6 +99,12 @@ type Node[H any] struct {
 	wildChild bool
 	nType     nodeType
 	priority  uint32
+
+	// fallback of paths under the node path (which ends with '/'), see
+	// AddFallback
+	fb     H
+	fbOK   bool
+	fbName string // name of the catch-all parameter
 }
 
 // Increments priority of the given child and reorders if necessary
@@ -127,12 +133,32 @@ func (n *Node[H]) incrementChildPrio(pos int) int {
 // AddRoute adds a node with the given handle to the path.
 // Not concurrency-safe!
 func (n *Node[H]) AddRoute(path string, handle H) {
+	n.add(path, handle, "")
+}
+
+// AddFallback adds a catch-all route, eg. "/static/*filepath", which doesn't
+// conflict with other routes: it is matched only if no other route matches
+// the path, and the one of the longest prefix is matched if there are more.
+// The prefix must not have wildcards.
+// Not concurrency-safe!
+func (n *Node[H]) AddFallback(path string, handle H) {
+	i := strings.IndexByte(path, '*')
+	if i < 1 || path[i-1] != '/' || len(path) < i+2 || strings.ContainsAny(path[:i], ":") ||
+		strings.ContainsAny(path[i+1:], ":*/") {
+		panic("fallback must be a catch-all route without other wildcards, eg. /static/*filepath, but got '" + path + "'")
+	}
+	n.add(path[:i], handle, path[i+1:])
+}
+
+// add adds handle to the path, or adds it as the fallback of paths under the
+// path if fbName (name of the catch-all parameter) isn't empty.
+func (n *Node[H]) add(path string, handle H, fbName string) {
 	fullPath := path
 	n.priority++
 
 	// Empty tree
 	if n.path == "" && n.indices == "" {
-		n.insertChild(path, fullPath, handle)
+		n.insertChild(path, fullPath, handle, fbName)
 		n.nType = root
 		return
 	}
@@ -155,6 +181,9 @@ walk:
 				h:         n.h,
 				ok:        n.ok,
 				priority:  n.priority - 1,
+				fb:        n.fb,
+				fbOK:      n.fbOK,
+				fbName:    n.fbName,
 			}
 
 			n.children = []*Node[H]{&child}
@@ -162,6 +191,7 @@ walk:
 			n.indices = string([]byte{n.path[i]})
 			n.path = path[:i]
 			n.h, n.ok = zero[H](), false
+			n.fb, n.fbOK, n.fbName = zero[H](), false, ""
 			n.wildChild = false
 		}
 
@@ -236,20 +266,31 @@ walk:
 				}
 				n = child
 			}
-			n.insertChild(path, fullPath, handle)
+			n.insertChild(path, fullPath, handle, fbName)
 			return
 		}
 
 		// Otherwise add handle to current node
-		if n.ok {
-			panic("a handle is already registered for path '" + fullPath + "'")
+		n.setHandle(fullPath, handle, fbName)
+		return
+	}
+}
+
+func (n *Node[H]) setHandle(fullPath string, handle H, fbName string) {
+	if fbName != "" {
+		if n.fbOK {
+			panic("a fallback is already registered for path '" + fullPath + "'")
 		}
-		n.h, n.ok = handle, true
+		n.fb, n.fbOK, n.fbName = handle, true, fbName
 		return
 	}
+	if n.ok {
+		panic("a handle is already registered for path '" + fullPath + "'")
+	}
+	n.h, n.ok = handle, true
 }
 
-func (n *Node[H]) insertChild(path, fullPath string, handle H) {
+func (n *Node[H]) insertChild(path, fullPath string, handle H, fbName string) {
 	for {
 		// Find prefix until first wildcard
 		wildcard, i, valid := findWildcard(path)
@@ -363,7 +404,7 @@ func (n *Node[H]) insertChild(path, fullPath string, handle H) {
 
 	// If no wildcard was found, simply insert the path and handle
 	n.path = path
-	n.h, n.ok = handle, true
+	n.setHandle(fullPath, handle, fbName)
 }
 
 // Route returns the handle registered with the given path (key).
@@ -380,14 +421,32 @@ func (n *Node[H]) Route(path string) (handle H, ok, tsr bool) {
 //
 // If no handle can be found, a TSR (trailing slash redirect) recommendation is
 // made if a handle exists with an extra (without the) trailing slash for the
-// given path.
+// given path. Otherwise the fallback of the longest prefix of the path is
+// returned if any (see AddFallback).
 func Route[T context, H any](n *Node[H], path string, ctx T) (handle H, ok, tsr bool) {
+	var fb *Node[H]
+	var rest string
+	if handle, ok, tsr = route(n, path, ctx, &fb, &rest); !ok && !tsr && fb != nil {
+		if notZero(ctx) {
+			ctx.UnderlyingSetPathParam(fb.fbName, "/"+rest)
+		}
+		return fb.fb, true, false
+	}
+	return
+}
+
+// route walks the tree for Route, and records the fallback of the longest
+// prefix of path in fb, with the rest of path in rest.
+func route[T context, H any](n *Node[H], path string, ctx T, fb **Node[H], rest *string) (handle H, ok, tsr bool) {
 walk: // Outer loop for walking the tree
 	for {
 		prefix := n.path
 		if len(path) > len(prefix) {
 			if path[:len(prefix)] == prefix {
 				path = path[len(prefix):]
+				if n.fbOK {
+					*fb, *rest = n, path
+				}
 
 				// If this node does not have a wildcard (param or catchAll)
 				// child, we can just look up the next child node and continue
@@ -422,7 +481,7 @@ walk: // Outer loop for walking the tree
 						// param value sharing its first byte, causing a spurious 404 because
 						// the iterative walk has no mechanism to backtrack to the param child.
 						if strings.HasPrefix(path, child.path) &&
-							(len(path) == len(child.path) || len(child.children) > 0) {
+							(len(path) == len(child.path) || len(child.children) > 0 || child.fbOK) {
 							n = child
 							continue walk
 						}
@@ -488,6 +547,10 @@ walk: // Outer loop for walking the tree
 			if handle, ok = n.h, n.ok; ok {
 				return
 			}
+			if n.fbOK {
+				*fb, *rest = n, ""
+				return
+			}
 
 			// If there is no handle for this route, but this route has a
 			// wildcard child, there must be a handle for this path with an
@@ -507,7 +570,7 @@ walk: // Outer loop for walking the tree
 			for i, c := range byteutil.Bytes(n.indices) {
 				if c == '/' {
 					n = n.children[i]
-					tsr = (len(n.path) == 1 && n.ok) ||
+					tsr = (len(n.path) == 1 && (n.ok || n.fbOK)) ||
 						(n.nType == catchAll && n.children[0].ok)
 					return
 				}
@@ -519,7 +582,7 @@ walk: // Outer loop for walking the tree
 		// extra trailing slash if a leaf exists for that path
 		tsr = (path == "/") ||
 			(len(prefix) == len(path)+1 && prefix[len(path)] == '/' &&
-				path == prefix[:len(prefix)-1] && n.ok)
+				path == prefix[:len(prefix)-1] && (n.ok || n.fbOK))
 		return
 	}
 }
@@ -661,3 +661,86 @@ func TestStaticAndParamSiblingsPanicDuplicateParamAfterStatic(t *testing.T) {
 	root.AddRoute("/model/:id", func(ctx *testContext) {})    // first param — OK
 	root.AddRoute("/model/:name", func(ctx *testContext) {})  // second param — must panic
 }
+
+// -----------------------------------------------------------------------------
+// AddFallback
+
+func TestFallback(t *testing.T) {
+	var root Node[string]
+	root.AddFallback("/static/*filepath", "static")
+	root.AddFallback("/static/img/*name", "img")
+	root.AddRoute("/static/app.js", "app")
+	root.AddRoute("/static-x", "x")
+	root.AddRoute("/:user", "user")
+	root.AddRoute("/:user/posts", "posts")
+
+	tests := []struct {
+		path, want, param, val string
+	}{
+		{"/static/app.js", "app", "", ""},
+		{"/static/css/a.css", "static", "filepath", "/css/a.css"},
+		{"/static/", "static", "filepath", "/"},
+		{"/static/img/logo.png", "img", "name", "/logo.png"},
+		{"/static/img/", "img", "name", "/"},
+		{"/static/imgx", "static", "filepath", "/imgx"},
+		{"/static-x", "x", "", ""},
+		{"/bob", "user", "user", "bob"},
+		{"/bob/posts", "posts", "user", "bob"},
+	}
+	for _, tt := range tests {
+		ctx := newTestCtx()
+		h, ok, tsr := Route(&root, tt.path, ctx)
+		if !ok || tsr || h != tt.want {
+			t.Fatalf("Route(%q) = %q, %v, %v, want %q", tt.path, h, ok, tsr, tt.want)
+		}
+		if tt.param != "" && ctx.params[tt.param] != tt.val {
+			t.Fatalf("Route(%q): param %s = %q, want %q", tt.path, tt.param, ctx.params[tt.param], tt.val)
+		}
+	}
+
+	if _, ok, tsr := Route(&root, "/static/img", newTestCtx()); ok || !tsr {
+		t.Fatal("Route(/static/img): expected TSR", ok, tsr)
+	}
+	if _, ok, _ := Route(&root, "/bob/likes", newTestCtx()); ok {
+		t.Fatal("Route(/bob/likes): unexpected handle")
+	}
+	if h, ok, _ := Route[*testContext](&root, "/static/x", nil); !ok || h != "static" {
+		t.Fatal("Route(/static/x) with nil ctx:", h, ok)
+	}
+}
+
+func TestFallbackTSR(t *testing.T) {
+	var root Node[string]
+	root.AddFallback("/assets/*filepath", "assets")
+	if _, ok, tsr := Route(&root, "/assets", newTestCtx()); ok || !tsr {
+		t.Fatal("Route(/assets): expected TSR", ok, tsr)
+	}
+	root.AddRoute("/assets-v2", "v2")
+	if _, ok, tsr := Route(&root, "/assets", newTestCtx()); ok || !tsr {
+		t.Fatal("Route(/assets) after split: expected TSR", ok, tsr)
+	}
+}
+
+func TestFallbackPanic(t *testing.T) {
+	for _, path := range []string{
+		"/static/", "/static*filepath", "/static/*", "/:dir/*filepath", "/static/*file/path",
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("AddFallback(%q): expected panic", path)
+				}
+			}()
+			var root Node[string]
+			root.AddFallback(path, "")
+		}()
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("AddFallback: expected panic for duplicate fallback")
+		}
+	}()
+	var root Node[string]
+	root.AddFallback("/static/*filepath", "a")
+	root.AddFallback("/static/*name", "b")
+}
@@ -0,0 +1,385 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"log"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// RateResult represents the result of taking a request from a rate limiter.
+type RateResult struct {
+	Allowed    bool
+	Limit      int           // maximum number of requests in a window (or burst)
+	Remaining  int           // remaining requests allowed now
+	Reset      time.Duration // time until the quota is fully restored
+	RetryAfter time.Duration // time until the next request is allowed, if !Allowed
+}
+
+// RateAlgorithm represents a rate limiting algorithm. See TokenBucket and
+// SlidingWindow.
+type RateAlgorithm interface {
+	// Take takes a request at now. state is the state of a key saved by the
+	// last call (nil for a new key). It returns the new state to save.
+	Take(state []byte, now time.Time) ([]byte, RateResult)
+
+	// TTL returns how long an idle state needs to be kept.
+	TTL() time.Duration
+}
+
+// TokenBucket returns the token bucket algorithm: the bucket holds at most
+// burst tokens, refilled at rate tokens per period. Each request takes one
+// token.
+func TokenBucket(rate int, per time.Duration, burst int) RateAlgorithm {
+	if rate <= 0 || per <= 0 || burst <= 0 {
+		log.Panicln("TokenBucket: invalid arguments")
+	}
+	return &tokenBucket{interval: per / time.Duration(rate), burst: burst}
+}
+
+type tokenBucket struct {
+	interval time.Duration // time to refill one token
+	burst    int
+}
+
+// state: tokens (float64) | last (unix nano)
+func (p *tokenBucket) Take(state []byte, now time.Time) ([]byte, RateResult) {
+	tokens, last := float64(p.burst), now.UnixNano()
+	if len(state) == 16 {
+		tokens = math.Float64frombits(binary.BigEndian.Uint64(state))
+		last = int64(binary.BigEndian.Uint64(state[8:]))
+	}
+	if elapsed := now.UnixNano() - last; elapsed > 0 {
+		tokens = math.Min(float64(p.burst), tokens+float64(elapsed)/float64(p.interval))
+	}
+	ret := RateResult{Limit: p.burst}
+	if tokens >= 1 {
+		tokens--
+		ret.Allowed = true
+	} else {
+		ret.RetryAfter = time.Duration((1 - tokens) * float64(p.interval))
+	}
+	ret.Remaining = int(tokens)
+	ret.Reset = time.Duration((float64(p.burst) - tokens) * float64(p.interval))
+	state = make([]byte, 16)
+	binary.BigEndian.PutUint64(state, math.Float64bits(tokens))
+	binary.BigEndian.PutUint64(state[8:], uint64(now.UnixNano()))
+	return state, ret
+}
+
+func (p *tokenBucket) TTL() time.Duration {
+	return p.interval * time.Duration(p.burst)
+}
+
+// SlidingWindow returns the sliding window algorithm: at most limit requests
+// are allowed in any window. The count of the previous fixed window is
+// weighted by its overlap with the sliding window.
+func SlidingWindow(limit int, window time.Duration) RateAlgorithm {
+	if limit <= 0 || window <= 0 {
+		log.Panicln("SlidingWindow: invalid arguments")
+	}
+	return &slidingWindow{limit: limit, window: window}
+}
+
+type slidingWindow struct {
+	limit  int
+	window time.Duration
+}
+
+// state: window start (unix nano) | previous count | current count
+func (p *slidingWindow) Take(state []byte, now time.Time) ([]byte, RateResult) {
+	w := int64(p.window)
+	start := now.UnixNano() / w * w
+	var prev, curr int64
+	if len(state) == 24 {
+		last := int64(binary.BigEndian.Uint64(state))
+		switch last {
+		case start:
+			prev = int64(binary.BigEndian.Uint64(state[8:]))
+			curr = int64(binary.BigEndian.Uint64(state[16:]))
+		case start - w:
+			prev = int64(binary.BigEndian.Uint64(state[16:]))
+		}
+	}
+	elapsed := now.UnixNano() - start
+	weight := float64(w-elapsed) / float64(w)
+	count := float64(prev)*weight + float64(curr)
+	ret := RateResult{Limit: p.limit, Reset: time.Duration(2*w - elapsed)}
+	if prev == 0 {
+		ret.Reset = time.Duration(w - elapsed)
+	}
+	if count+1 <= float64(p.limit) {
+		curr++
+		count++
+		ret.Allowed = true
+	} else if curr >= int64(p.limit) || prev == 0 {
+		ret.RetryAfter = time.Duration(w - elapsed)
+	} else {
+		// wait until the weighted count of the previous window decreases enough
+		need := (count + 1 - float64(p.limit)) / float64(prev) * float64(w)
+		ret.RetryAfter = time.Duration(math.Ceil(need))
+	}
+	ret.Remaining = max(p.limit-int(math.Ceil(count)), 0)
+	state = make([]byte, 24)
+	binary.BigEndian.PutUint64(state, uint64(start))
+	binary.BigEndian.PutUint64(state[8:], uint64(prev))
+	binary.BigEndian.PutUint64(state[16:], uint64(curr))
+	return state, ret
+}
+
+func (p *slidingWindow) TTL() time.Duration {
+	return 2 * p.window
+}
+
+// -----------------------------------------------------------------------------
+
+// RateStore represents a storage of rate limiter states. Implementations for
+// external storages (eg. Redis) must update a key atomically.
+type RateStore interface {
+	// Update atomically replaces the state of key by update(state). state is
+	// nil if key doesn't exist or it expired. The new state expires after ttl.
+	Update(key string, now time.Time, ttl time.Duration, update func(state []byte) []byte) error
+}
+
+// MemoryRateStore is an in-memory RateStore.
+type MemoryRateStore struct {
+	states map[string]memRateState
+	lastGC time.Time
+	mutex  sync.Mutex
+}
+
+type memRateState struct {
+	data   []byte
+	expire time.Time
+}
+
+// NewMemoryRateStore creates a MemoryRateStore.
+func NewMemoryRateStore() *MemoryRateStore {
+	return &MemoryRateStore{states: make(map[string]memRateState)}
+}
+
+// Update implements RateStore.Update.
+func (p *MemoryRateStore) Update(key string, now time.Time, ttl time.Duration, update func(state []byte) []byte) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	var state []byte
+	if s, ok := p.states[key]; ok && now.Before(s.expire) {
+		state = s.data
+	}
+	p.states[key] = memRateState{data: update(state), expire: now.Add(ttl)}
+	if now.Sub(p.lastGC) > time.Minute {
+		p.lastGC = now
+		for k, s := range p.states {
+			if !now.Before(s.expire) {
+				delete(p.states, k)
+			}
+		}
+	}
+	return nil
+}
+
+// Len returns the number of keys in the store (including expired ones not
+// removed yet).
+func (p *MemoryRateStore) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.states)
+}
+
+// -----------------------------------------------------------------------------
+
+// RateLimitOptions represents options of the rate limiting middleware.
+type RateLimitOptions struct {
+	// Algorithm is the rate limiting algorithm. It is required.
+	Algorithm RateAlgorithm
+
+	// Name prefixes keys of this limiter, so that limiters can share a store.
+	Name string
+
+	// Key returns the key of a request. Requests with an empty key are not
+	// limited. It is KeyByIP() by default.
+	Key func(r *http.Request) string
+
+	// Store is the storage of limiter states. It is a MemoryRateStore by
+	// default.
+	Store RateStore
+
+	// Now returns the current time. It is time.Now by default.
+	Now func() time.Time
+
+	// ErrorHandler is called when a request is limited. If it is nil, a 429
+	// Too Many Requests error is returned.
+	ErrorHandler http.Handler
+}
+
+// RateLimit returns a middleware which limits the request rate of each key.
+// It sets RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers,
+// and Retry-After if a request is limited. Requests are allowed if the store
+// fails. For example:
+//
+//	y.With(yap.RateLimit(yap.RateLimitOptions{
+//		Algorithm: yap.SlidingWindow(5, time.Minute),
+//	})).POST("/login", login)
+func RateLimit(opts RateLimitOptions) func(h http.Handler) http.Handler {
+	if opts.Algorithm == nil {
+		log.Panicln("RateLimit: no algorithm")
+	}
+	if opts.Key == nil {
+		opts.Key = KeyByIP()
+	}
+	if opts.Store == nil {
+		opts.Store = NewMemoryRateStore()
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	alg, ttl := opts.Algorithm, opts.Algorithm.TTL()
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := opts.Key(r)
+			if key == "" {
+				h.ServeHTTP(w, r)
+				return
+			}
+			var ret RateResult
+			now := opts.Now()
+			err := opts.Store.Update(opts.Name+key, now, ttl, func(state []byte) []byte {
+				state, ret = alg.Take(state, now)
+				return state
+			})
+			if err != nil {
+				log.Println("RateLimit:", err)
+				h.ServeHTTP(w, r)
+				return
+			}
+			header := w.Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(ret.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(ret.Remaining))
+			header.Set("RateLimit-Reset", seconds(ret.Reset))
+			if ret.Allowed {
+				h.ServeHTTP(w, r)
+				return
+			}
+			header.Set("Retry-After", seconds(ret.RetryAfter))
+			if opts.ErrorHandler != nil {
+				opts.ErrorHandler.ServeHTTP(w, r)
+			} else {
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			}
+		})
+	}
+}
+
+// seconds returns d in seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10)
+}
+
+// -----------------------------------------------------------------------------
+
+// KeyByIP returns a key function by the client IP. If the request comes from a
+// trusted proxy, the client IP is read from the forwarding headers (see
+// Context.ClientIP). Trusted proxies are those of the engine (see
+// Engine.SetTrustedProxies), unless trustedProxies (CIDRs or IPs) overrides
+// them.
+func KeyByIP(trustedProxies ...string) func(r *http.Request) string {
+	trusted := parsePrefixes(trustedProxies)
+	return func(r *http.Request) string {
+		return "ip:" + clientIP(r, trustedOf(r, trusted))
+	}
+}
+
+// KeyByUser returns a key function by the subject of the authenticated
+// identity (see Auth). Anonymous requests get an empty key.
+func KeyByUser() func(r *http.Request) string {
+	return func(r *http.Request) string {
+		if claims, _ := r.Context().Value(claimsKey{}).(Claims); claims != nil {
+			return "user:" + claims.Subject()
+		}
+		return ""
+	}
+}
+
+// KeyByHeader returns a key function by a request header, eg. an API key. The
+// header value is hashed. Requests without the header get an empty key.
+func KeyByHeader(name string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		v := r.Header.Get(name)
+		if v == "" {
+			return ""
+		}
+		sum := sha256.Sum256([]byte(v))
+		return "header:" + base64.RawURLEncoding.EncodeToString(sum[:16])
+	}
+}
+
+// KeyFirst returns a key function which returns the first non-empty key of
+// keys, eg. KeyFirst(KeyByUser(), KeyByIP()).
+func KeyFirst(keys ...func(r *http.Request) string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		for _, key := range keys {
+			if k := key(r); k != "" {
+				return k
+			}
+		}
+		return ""
+	}
+}
+
+func parsePrefixes(cidrs []string) []netip.Prefix {
+	ret := make([]netip.Prefix, 0, len(cidrs))
+	for _, s := range cidrs {
+		var prefix netip.Prefix
+		var err error
+		if strings.Contains(s, "/") {
+			prefix, err = netip.ParsePrefix(s)
+		} else {
+			var addr netip.Addr
+			if addr, err = netip.ParseAddr(s); err == nil {
+				prefix = netip.PrefixFrom(addr, addr.BitLen())
+			}
+		}
+		if err != nil {
+			log.Panicln("invalid trusted proxy:", err)
+		}
+		ret = append(ret, prefix.Masked())
+	}
+	return ret
+}
+
+func isTrusted(addr netip.Addr, trusted []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// -----------------------------------------------------------------------------
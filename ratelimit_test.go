@@ -0,0 +1,185 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goplus/yap"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (p *fakeClock) Now() time.Time {
+	return p.now
+}
+
+func (p *fakeClock) Add(d time.Duration) {
+	p.now = p.now.Add(d)
+}
+
+func newClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func limitedEngine(opts yap.RateLimitOptions) *yap.Engine {
+	e := newEngine()
+	e.Use(yap.Auth(yap.AuthOptions{}, users))
+	ok := func(ctx *yap.Context) { ctx.TEXT(200, "text/plain", "ok") }
+	e.GET("/public", ok)
+	e.With(yap.RateLimit(opts)).POST("/login", ok)
+	return e
+}
+
+func TestRateLimitTokenBucket(t *testing.T) {
+	clock := newClock()
+	e := limitedEngine(yap.RateLimitOptions{
+		Algorithm: yap.TokenBucket(1, time.Second, 3),
+		Now:       clock.Now,
+	})
+	for i := 0; i < 3; i++ {
+		if w := serve(e, "POST", "/login"); w.Code != 200 {
+			t.Fatal("burst:", i, w.Code)
+		}
+	}
+	w := serve(e, "POST", "/login")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatal("limited:", w.Code)
+	}
+	h := w.Header()
+	if h.Get("RateLimit-Limit") != "3" || h.Get("RateLimit-Remaining") != "0" ||
+		h.Get("Retry-After") != "1" || h.Get("RateLimit-Reset") != "3" {
+		t.Fatal("headers:", h)
+	}
+	if w := serve(e, "GET", "/public"); w.Code != 200 {
+		t.Fatal("unlimited route:", w.Code)
+	}
+	clock.Add(time.Second)
+	if w := serve(e, "POST", "/login"); w.Code != 200 {
+		t.Fatal("refilled:", w.Code)
+	}
+	if w := serve(e, "POST", "/login"); w.Code != http.StatusTooManyRequests {
+		t.Fatal("limited again:", w.Code)
+	}
+}
+
+func TestRateLimitSlidingWindow(t *testing.T) {
+	clock := newClock()
+	e := limitedEngine(yap.RateLimitOptions{
+		Algorithm: yap.SlidingWindow(2, time.Minute),
+		Now:       clock.Now,
+	})
+	for i, code := range []int{200, 200, 429} {
+		if w := serve(e, "POST", "/login"); w.Code != code {
+			t.Fatal("window 1:", i, w.Code)
+		}
+	}
+	// half of the previous window still counts
+	clock.Add(90 * time.Second)
+	for i, code := range []int{200, 429} {
+		if w := serve(e, "POST", "/login"); w.Code != code {
+			t.Fatal("window 2:", i, w.Code)
+		}
+	}
+	clock.Add(2 * time.Minute)
+	if w := serve(e, "POST", "/login"); w.Code != 200 {
+		t.Fatal("window 4:", w.Code)
+	}
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	clock := newClock()
+	e := limitedEngine(yap.RateLimitOptions{
+		Algorithm: yap.TokenBucket(1, time.Hour, 1),
+		Key:       yap.KeyFirst(yap.KeyByUser(), yap.KeyByIP("10.0.0.0/8")),
+		Now:       clock.Now,
+	})
+	post := func(remote, xff, user string) int {
+		req := httptest.NewRequest("POST", "/login", nil)
+		req.RemoteAddr = remote + ":1234"
+		if xff != "" {
+			req.Header.Set("X-Forwarded-For", xff)
+		}
+		if user != "" {
+			req.Header.Set("X-User", user)
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w.Code
+	}
+	for i, c := range []struct {
+		remote, xff, user string
+		code              int
+	}{
+		{"1.2.3.4", "", "", 200},
+		{"1.2.3.4", "", "", 429},
+		{"10.0.0.1", "1.2.3.4", "", 429},           // via trusted proxy
+		{"10.0.0.1", "1.2.3.4, 10.0.0.2", "", 429}, // via trusted proxies
+		{"10.0.0.1", "5.6.7.8, 1.2.3.4", "", 429},  // spoofed hop is ignored
+		{"5.6.7.8", "1.2.3.4", "", 200},            // untrusted proxy
+		{"1.2.3.4", "", "admin", 200},
+		{"1.2.3.4", "", "admin", 429},
+		{"1.2.3.4", "", "reader", 200},
+	} {
+		if code := post(c.remote, c.xff, c.user); code != c.code {
+			t.Fatal(i, c.remote, c.xff, c.user, code)
+		}
+	}
+
+	key := yap.KeyByHeader("X-API-Key")
+	req := httptest.NewRequest("GET", "/", nil)
+	if key(req) != "" {
+		t.Fatal("KeyByHeader: empty")
+	}
+	req.Header.Set("X-API-Key", "s3cret")
+	if k := key(req); k == "" || k == "header:s3cret" {
+		t.Fatal("KeyByHeader:", k)
+	}
+}
+
+func TestMemoryRateStore(t *testing.T) {
+	clock := newClock()
+	store := yap.NewMemoryRateStore()
+	e := newEngine()
+	e.Use(yap.RateLimit(yap.RateLimitOptions{
+		Algorithm: yap.SlidingWindow(1, time.Second),
+		Store:     store,
+		Now:       clock.Now,
+		ErrorHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(503)
+		}),
+	}))
+	e.GET("/", func(ctx *yap.Context) {})
+	if w := serve(e, "GET", "/"); w.Code != 200 {
+		t.Fatal("first:", w.Code)
+	}
+	if w := serve(e, "GET", "/"); w.Code != 503 {
+		t.Fatal("ErrorHandler:", w.Code)
+	}
+	clock.Add(2 * time.Minute)
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "5.6.7.8:1234"
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	if n := store.Len(); n != 1 {
+		t.Fatal("gc:", n)
+	}
+}
@@ -0,0 +1,252 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+
+// SetTrustedProxies sets proxies (CIDRs or IPs) trusted to provide the client
+// IP, scheme and host by the Forwarded, X-Forwarded-For, X-Real-IP,
+// X-Forwarded-Proto and X-Forwarded-Host headers, eg.
+//
+//	y.SetTrustedProxies("10.0.0.0/8", "127.0.0.1")
+//
+// These headers of requests from other addresses are ignored. See
+// Context.ClientIP, Context.Scheme and Context.Host. The proxies are also the
+// default trusted proxies of KeyByIP and AccessLog used on the engine.
+func (p *Engine) SetTrustedProxies(cidrs ...string) {
+	p.trusted = parsePrefixes(cidrs)
+}
+
+// ClientIP returns the IP of the client. If the request comes from a trusted
+// proxy (see Engine.SetTrustedProxies), it is read from the forwarding
+// headers, from right to left while the hops are trusted proxies.
+func (p *Context) ClientIP() string {
+	return resolveRemote(p.Request, p.engine.trusted).ip
+}
+
+// Scheme returns the scheme ("http" or "https") used by the client. See
+// ClientIP.
+func (p *Context) Scheme() string {
+	return resolveRemote(p.Request, p.engine.trusted).scheme
+}
+
+// Host returns the host (with the port if any) requested by the client. See
+// ClientIP.
+func (p *Context) Host() string {
+	return resolveRemote(p.Request, p.engine.trusted).host
+}
+
+// remoteInfo is the client info of a request.
+type remoteInfo struct {
+	ip, scheme, host string
+}
+
+// forwardedHop is a hop of a forwarded request: the node it comes from, and
+// the scheme and host requested by it.
+type forwardedHop struct {
+	node, proto, host string
+}
+
+// resolveRemote returns the client info of r. The forwarding headers are read
+// only if r comes from a trusted proxy.
+func resolveRemote(r *http.Request, trusted []netip.Prefix) remoteInfo {
+	ret := remoteInfo{ip: r.RemoteAddr, scheme: "http", host: r.Host}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ret.ip = host
+	}
+	if r.TLS != nil {
+		ret.scheme = "https"
+	}
+	addr, err := netip.ParseAddr(ret.ip)
+	if err != nil || !isTrusted(addr, trusted) {
+		return ret
+	}
+	hops := forwardedHops(r.Header)
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := hops[i]
+		if hop.proto == "http" || hop.proto == "https" {
+			ret.scheme = hop.proto
+		}
+		if validHost(hop.host) {
+			ret.host = hop.host
+		}
+		a, ok := parseNode(hop.node)
+		if !ok {
+			break
+		}
+		ret.ip = a.Unmap().String()
+		if !isTrusted(a, trusted) {
+			break
+		}
+	}
+	return ret
+}
+
+// forwardedHops returns hops in the Forwarded header, or else in the
+// X-Forwarded-For (or X-Real-IP), X-Forwarded-Proto and X-Forwarded-Host
+// headers.
+func forwardedHops(h http.Header) (hops []forwardedHop) {
+	if vals := h.Values("Forwarded"); vals != nil {
+		for _, elem := range splitQuoted(strings.Join(vals, ","), ',') {
+			var hop forwardedHop
+			for _, pair := range splitQuoted(elem, ';') {
+				k, v, _ := strings.Cut(strings.TrimSpace(pair), "=")
+				v = strings.Trim(v, `"`)
+				switch strings.ToLower(k) {
+				case "for":
+					hop.node = v
+				case "proto":
+					hop.proto = strings.ToLower(v)
+				case "host":
+					hop.host = v
+				}
+			}
+			hops = append(hops, hop)
+		}
+		return
+	}
+	nodes := headerList(h, "X-Forwarded-For")
+	if nodes == nil {
+		nodes = headerList(h, "X-Real-IP")
+	}
+	protos, hosts := headerList(h, "X-Forwarded-Proto"), headerList(h, "X-Forwarded-Host")
+	if nodes == nil && (protos != nil || hosts != nil) {
+		nodes = []string{""} // the scheme and host only
+	}
+	for i, node := range nodes {
+		hops = append(hops, forwardedHop{
+			node:  node,
+			proto: strings.ToLower(listItem(protos, i, len(nodes))),
+			host:  listItem(hosts, i, len(nodes)),
+		})
+	}
+	return
+}
+
+// listItem returns the i-th item of list if it has n items like hops, or else
+// the first one (set by the first proxy).
+func listItem(list []string, i, n int) string {
+	if len(list) == n {
+		return list[i]
+	}
+	if list != nil {
+		return list[0]
+	}
+	return ""
+}
+
+func headerList(h http.Header, key string) []string {
+	vals := h.Values(key)
+	if vals == nil {
+		return nil
+	}
+	list := strings.Split(strings.Join(vals, ","), ",")
+	for i, v := range list {
+		list[i] = strings.TrimSpace(v)
+	}
+	return list
+}
+
+// splitQuoted splits s by sep out of quoted strings.
+func splitQuoted(s string, sep byte) (ret []string) {
+	quoted, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				ret = append(ret, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(ret, s[start:])
+}
+
+// parseNode parses a node of a hop, eg. "192.0.2.60", "192.0.2.60:8080" or
+// "[2001:db8::1]:4711".
+func parseNode(node string) (netip.Addr, bool) {
+	if ap, err := netip.ParseAddrPort(node); err == nil {
+		return ap.Addr(), true
+	}
+	a, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(node, "["), "]"))
+	return a, err == nil
+}
+
+func validHost(host string) bool {
+	return host != "" && !strings.ContainsAny(host, "/\\@?# \t")
+}
+
+// clientIP returns the client IP of r. See Context.ClientIP.
+func clientIP(r *http.Request, trusted []netip.Prefix) string {
+	return resolveRemote(r, trusted).ip
+}
+
+type trustedKey struct{}
+
+// withTrusted returns r carrying trusted proxies of the engine, which are the
+// default ones of middlewares (see KeyByIP and AccessLog).
+func (p *Engine) withTrusted(r *http.Request) *http.Request {
+	if len(p.trusted) == 0 || r.Context().Value(trustedKey{}) != nil {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), trustedKey{}, p.trusted))
+}
+
+// trustedOf returns trusted proxies of a middleware, which are those of the
+// engine serving r if the middleware has none.
+func trustedOf(r *http.Request, trusted []netip.Prefix) []netip.Prefix {
+	if len(trusted) > 0 {
+		return trusted
+	}
+	ret, _ := r.Context().Value(trustedKey{}).([]netip.Prefix)
+	return ret
+}
+
+// -----------------------------------------------------------------------------
+
+// redirect replies to r with a redirect to target. If r is forwarded by a
+// trusted proxy with another scheme or host, target is made absolute by them.
+func (p *Engine) redirect(w http.ResponseWriter, r *http.Request, target string, code int) {
+	if p.trusted != nil {
+		remote := resolveRemote(r, p.trusted)
+		direct := "http"
+		if r.TLS != nil {
+			direct = "https"
+		}
+		if remote.scheme != direct || remote.host != r.Host {
+			if u, err := url.Parse(target); err == nil && u.Scheme == "" && u.Host == "" {
+				u = r.URL.ResolveReference(u)
+				u.Scheme, u.Host = remote.scheme, remote.host
+				target = u.String()
+			}
+		}
+	}
+	http.Redirect(w, r, target, code)
+}
+
+// -----------------------------------------------------------------------------
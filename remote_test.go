@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goplus/yap"
+)
+
+func TestClientIP(t *testing.T) {
+	e := yap.New()
+	e.SetTrustedProxies("10.0.0.0/8", "::1")
+	e.GET("/who", func(ctx *yap.Context) {
+		ctx.Text__2(ctx.ClientIP() + " " + ctx.Scheme() + "://" + ctx.Host())
+	})
+	for i, c := range []struct {
+		remote string
+		header []string
+		want   string
+	}{
+		{"1.2.3.4:1000", nil, "1.2.3.4 http://example.com"},
+		{"1.2.3.4:1000", []string{"X-Forwarded-For", "5.6.7.8", "X-Forwarded-Proto", "https"}, "1.2.3.4 http://example.com"},
+		{"10.0.0.1:1000", []string{"X-Forwarded-For", "5.6.7.8, 1.2.3.4, 10.0.0.2"}, "1.2.3.4 http://example.com"},
+		{"10.0.0.1:1000", []string{"X-Forwarded-For", "1.2.3.4", "X-Forwarded-Proto", "https", "X-Forwarded-Host", "blog.dev"}, "1.2.3.4 https://blog.dev"},
+		{"10.0.0.1:1000", []string{"X-Forwarded-Proto", "https"}, "10.0.0.1 https://example.com"},
+		{"10.0.0.1:1000", []string{"X-Real-IP", "1.2.3.4"}, "1.2.3.4 http://example.com"},
+		{"10.0.0.1:1000", []string{"X-Forwarded-Host", "evil/path"}, "10.0.0.1 http://example.com"},
+		{"[::1]:1000", []string{"Forwarded", `for="[2001:db8::1]:4711";proto=https;host="blog.dev", for=10.0.0.2;proto=http`, "X-Forwarded-For", "9.9.9.9"}, "2001:db8::1 https://blog.dev"},
+		{"10.0.0.1:1000", []string{"Forwarded", "for=unknown;proto=https"}, "10.0.0.1 https://example.com"},
+	} {
+		w := serveWith(remoteHandler(e, c.remote), "GET", "/who", c.header...)
+		if w.Body.String() != c.want {
+			t.Fatal(i, w.Body.String())
+		}
+	}
+
+	if key := yap.KeyByIP("10.0.0.1"); key(remoteRequest("10.0.0.1:1", "X-Real-IP", "1.2.3.4")) != "ip:1.2.3.4" {
+		t.Fatal("KeyByIP: X-Real-IP")
+	}
+}
+
+func TestEngineTrustedProxies(t *testing.T) {
+	var buf bytes.Buffer
+	e := yap.New()
+	e.SetTrustedProxies("10.0.0.0/8")
+	e.Use(yap.AccessLog(yap.AccessLogOptions{Logger: slog.New(slog.NewJSONHandler(&buf, nil))}))
+	byIP, byOwn := yap.KeyByIP(), yap.KeyByIP("192.168.0.0/16")
+	e.GET("/", func(ctx *yap.Context) {
+		ctx.Text__2(byIP(ctx.Request) + " " + byOwn(ctx.Request))
+	})
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, remoteRequest("10.0.0.1:1", "X-Forwarded-For", "1.2.3.4"))
+	if w.Body.String() != "ip:1.2.3.4 ip:10.0.0.1" {
+		t.Fatal("KeyByIP:", w.Body.String())
+	}
+	if rec := logRecords(t, &buf)[0]; rec["ip"] != "1.2.3.4" {
+		t.Fatal("AccessLog:", rec)
+	}
+
+	// middlewares of Engine.Handler (eg. by Run)
+	h := e.Handler(yap.AccessLog(yap.AccessLogOptions{Logger: slog.New(slog.NewJSONHandler(&buf, nil))}))
+	h.ServeHTTP(httptest.NewRecorder(), remoteRequest("10.0.0.1:1", "X-Forwarded-For", "1.2.3.4"))
+	for _, rec := range logRecords(t, &buf) {
+		if rec["ip"] != "1.2.3.4" {
+			t.Fatal("AccessLog of Handler:", rec)
+		}
+	}
+	if key := yap.KeyByIP(); key(remoteRequest("10.0.0.1:1", "X-Forwarded-For", "1.2.3.4")) != "ip:10.0.0.1" {
+		t.Fatal("KeyByIP without an engine")
+	}
+}
+
+func TestForwardedRedirect(t *testing.T) {
+	e := yap.New()
+	e.SetTrustedProxies("10.0.0.1")
+	e.GET("/login", func(ctx *yap.Context) {
+		ctx.Redirect("home")
+	})
+	e.GET("/docs/", func(ctx *yap.Context) {})
+
+	h := remoteHandler(e, "10.0.0.1:1000")
+	header := []string{"X-Forwarded-Proto", "https", "X-Forwarded-Host", "blog.dev"}
+	if w := serveWith(h, "GET", "/login?x=1", header...); w.Header().Get("Location") != "https://blog.dev/home" {
+		t.Fatal("Redirect:", w.Header().Get("Location"))
+	}
+	if w := serveWith(h, "GET", "/docs?x=1", header...); w.Header().Get("Location") != "https://blog.dev/docs/?x=1" {
+		t.Fatal("RedirectTrailingSlash:", w.Header().Get("Location"))
+	}
+	if w := serveWith(h, "GET", "/login"); w.Header().Get("Location") != "/home" {
+		t.Fatal("Redirect:", w.Header().Get("Location"))
+	}
+}
+
+// remoteHandler serves requests from the remote address.
+func remoteHandler(h http.Handler, remote string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.RemoteAddr = remote
+		h.ServeHTTP(w, r)
+	})
+}
+
+func remoteRequest(remote string, header ...string) *http.Request {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = remote
+	for i := 0; i < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	return req
+}
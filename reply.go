@@ -0,0 +1,232 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+)
+
+// -----------------------------------------------------------------------------
+
+// HTTPError represents an error with a HTTP status code.
+type HTTPError struct {
+	Code    int
+	Message string
+}
+
+// NewHTTPError creates a HTTPError. If msg is empty, the status text of code
+// is used.
+func NewHTTPError(code int, msg string) *HTTPError {
+	if msg == "" {
+		msg = http.StatusText(code)
+	}
+	return &HTTPError{Code: code, Message: msg}
+}
+
+func (e *HTTPError) Error() string {
+	return e.Message
+}
+
+// StatusCode returns the HTTP status code of the error.
+func (e *HTTPError) StatusCode() int {
+	return e.Code
+}
+
+// SetErrorRenderer sets the function rendering errors replied by handlers
+// (see Context.Reply and Context.RenderError).
+func (p *Engine) SetErrorRenderer(render func(ctx *Context, err error)) {
+	p.errRender = render
+}
+
+// SetDevMode enables or disables the development mode. In the development
+// mode, messages of 5xx errors are shown to clients, and requests which a
+// handler doesn't answer are reported with 500 Internal Server Error.
+func (p *Engine) SetDevMode(dev bool) {
+	p.dev = dev
+}
+
+// RenderError replies to the request with err by the error renderer (see
+// Engine.SetErrorRenderer). By default, the status code is that of err if it
+// has a StatusCode method (eg. HTTPError), otherwise 500. The error message
+// (hidden for 5xx errors except in the development mode) is replied in JSON
+// like {"error": "..."} if the client accepts JSON, otherwise in plain text.
+func (p *Context) RenderError(err error) {
+	if render := p.engine.errRender; render != nil {
+		render(p, err)
+		return
+	}
+	code, msg := http.StatusInternalServerError, err.Error()
+	var se interface{ StatusCode() int }
+	if errors.As(err, &se) {
+		code = se.StatusCode()
+	}
+	if code >= 500 && !p.engine.dev {
+		msg = http.StatusText(code)
+	}
+	if p.acceptsJSON(true) {
+		p.JSON(code, map[string]string{"error": msg})
+	} else {
+		p.TEXT(code, "text/plain; charset=utf-8", msg)
+	}
+}
+
+// acceptsJSON reports whether the client accepts JSON explicitly (by
+// "application/json"), or, if explicit is false, accepts any type (by
+// "application/*", "*/*" or no Accept header).
+func (p *Context) acceptsJSON(explicit bool) bool {
+	if p.Accept("application/json") != "" {
+		return true
+	}
+	return !explicit && (p.Request.Header.Get("Accept") == "" || p.Accept("application/*", "*/*") != "")
+}
+
+// Reply replies to the request with a value returned by a handler:
+//   - nil: 204 No Content (if code is 200)
+//   - error: rendered by RenderError (code is ignored)
+//   - string: plain text
+//   - []byte: binary data
+//   - io.Reader: a stream (see STREAM)
+//   - others (eg. a struct or a map): JSON if the client accepts it, otherwise
+//     406 Not Acceptable rendered by RenderError
+func (p *Context) Reply(code int, v any) {
+	switch v := v.(type) {
+	case nil:
+		if code == http.StatusOK {
+			code = http.StatusNoContent
+		}
+		p.ResponseWriter.WriteHeader(code)
+	case error:
+		p.RenderError(v)
+	case string:
+		p.TEXT(code, "text/plain; charset=utf-8", v)
+	case []byte:
+		p.DATA(code, "application/octet-stream", v)
+	case io.Reader:
+		p.STREAM(code, "", v, nil)
+	default:
+		if !p.acceptsJSON(false) {
+			p.RenderError(NewHTTPError(http.StatusNotAcceptable, ""))
+			return
+		}
+		p.JSON(code, v)
+	}
+}
+
+// replyResult replies to the request with the result of a handler, which is
+// v, or err if it isn't nil.
+func (p *Context) replyResult(v any, err error) {
+	if err != nil {
+		p.RenderError(err)
+		return
+	}
+	p.Reply(http.StatusOK, v)
+}
+
+var (
+	tyContext = reflect.TypeOf((*Context)(nil))
+	tyError   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Returns adapts fn, a handler returning its result, to a handle of routes,
+// eg.
+//
+//	e.GET("/p/:id", yap.Returns(func(ctx *yap.Context) (*Article, error) {
+//		return getArticle(ctx.Param("id"))
+//	}))
+//
+// fn is a func(ctx *Context) with results of a value, (value, error) or
+// (code int, value). The value is replied by Context.Reply, and a non-nil
+// error by Context.RenderError.
+func Returns(fn any) func(ctx *Context) {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.In(0) != tyContext || t.IsVariadic() {
+		log.Panicln("Returns: fn must be a func(ctx *yap.Context) with results, but got", t)
+	}
+	call := func(ctx *Context) []reflect.Value {
+		return v.Call([]reflect.Value{reflect.ValueOf(ctx)})
+	}
+	switch {
+	case t.NumOut() == 1:
+		return func(ctx *Context) {
+			ctx.Reply(http.StatusOK, call(ctx)[0].Interface())
+		}
+	case t.NumOut() == 2 && t.Out(1) == tyError:
+		return func(ctx *Context) {
+			rets := call(ctx)
+			err, _ := rets[1].Interface().(error)
+			ctx.replyResult(rets[0].Interface(), err)
+		}
+	case t.NumOut() == 2 && t.Out(0).Kind() == reflect.Int:
+		return func(ctx *Context) {
+			rets := call(ctx)
+			ctx.Reply(int(rets[0].Int()), rets[1].Interface())
+		}
+	}
+	log.Panicln("Returns: results of fn must be a value, (value, error) or (code int, value), but got", t)
+	return nil
+}
+
+// A YAP handler can return its result by defining a Result method, which is
+// called after Main, instead of replying in Main, eg. in get_p_#id.yap:
+//
+//	func Result() (any, error) {
+//		return getArticle(${id})
+//	}
+//
+// Results of Result are replied as those of a func passed to Returns.
+type iHandlerResult interface {
+	Result() any
+}
+
+type iHandlerResultErr interface {
+	Result() (any, error)
+}
+
+type iHandlerResultCode interface {
+	Result() (int, any)
+}
+
+// replyHandlerResult replies to the request with the result of handler h, if
+// it defines a Result method.
+func replyHandlerResult(ctx *Context, h HandlerProto) {
+	switch r := h.(type) {
+	case iHandlerResult:
+		ctx.Reply(http.StatusOK, r.Result())
+	case iHandlerResultErr:
+		ctx.replyResult(r.Result())
+	case iHandlerResultCode:
+		ctx.Reply(r.Result())
+	}
+}
+
+// checkAnswered reports a request which isn't answered by the handler of the
+// route pattern.
+func (p *Context) checkAnswered(w *responseWriter, pattern string) {
+	if w.wroteHeader {
+		return
+	}
+	msg := "yap: " + p.Method + " " + p.URL.Path + " (route " + pattern + ") is not answered by its handler"
+	log.Println(msg)
+	http.Error(w, msg, http.StatusInternalServerError)
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,223 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/goplus/yap"
+)
+
+type article struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+func TestReply(t *testing.T) {
+	e := yap.New()
+	e.GET("/p/:id", func(ctx *yap.Context) {
+		switch id := ctx.Param("id"); id {
+		case "missing":
+			ctx.Reply__1(yap.NewHTTPError(404, "article not found"))
+		case "broken":
+			ctx.Reply__1(fmt.Errorf("load article: %w", errors.New("db is down")))
+		default:
+			ctx.Reply__1(&article{ID: id, Title: "Hello"})
+		}
+	})
+	e.POST("/p", func(ctx *yap.Context) {
+		ctx.Reply__0(201, "created")
+	})
+	e.DELETE("/p/:id", func(ctx *yap.Context) {
+		ctx.Reply__1(nil)
+	})
+	e.GET("/raw", func(ctx *yap.Context) {
+		ctx.Reply__1([]byte{1, 2})
+	})
+
+	for _, c := range []struct {
+		method, path, accept string
+		code                 int
+		ctype, body          string
+	}{
+		{"GET", "/p/1", "", 200, "application/json", `{"id":"1","title":"Hello"}`},
+		{"GET", "/p/missing", "", 404, "text/plain; charset=utf-8", "article not found"},
+		{"GET", "/p/missing", "application/json", 404, "application/json", `{"error":"article not found"}`},
+		{"GET", "/p/broken", "", 500, "text/plain; charset=utf-8", "Internal Server Error"},
+		{"POST", "/p", "", 201, "text/plain; charset=utf-8", "created"},
+		{"DELETE", "/p/1", "", 204, "", ""},
+		{"GET", "/raw", "", 200, "application/octet-stream", "\x01\x02"},
+	} {
+		w := serveWith(e, c.method, c.path, "Accept", c.accept)
+		if w.Code != c.code || w.Header().Get("Content-Type") != c.ctype || w.Body.String() != c.body {
+			t.Fatal(c.method, c.path, w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+	}
+
+	e.SetDevMode(true)
+	if w := serveWith(e, "GET", "/p/broken"); w.Body.String() != "load article: db is down" {
+		t.Fatal("dev mode:", w.Body.String())
+	}
+	e.SetErrorRenderer(func(ctx *yap.Context, err error) {
+		ctx.TEXT(418, "text/plain", "oops: "+err.Error())
+	})
+	if w := serveWith(e, "GET", "/p/missing"); w.Code != 418 || w.Body.String() != "oops: article not found" {
+		t.Fatal("SetErrorRenderer:", w.Code, w.Body.String())
+	}
+}
+
+func TestUnanswered(t *testing.T) {
+	e := yap.New()
+	e.GET("/p/:id", func(ctx *yap.Context) {})
+	if w := serveWith(e, "GET", "/p/1"); w.Code != 200 || w.Body.Len() != 0 {
+		t.Fatal("GET /p/1:", w.Code, w.Body.String())
+	}
+	e.SetDevMode(true)
+	w := serveWith(e, "GET", "/p/1")
+	if w.Code != 500 || !strings.Contains(w.Body.String(), "GET /p/1 (route /p/:id) is not answered") {
+		t.Fatal("dev mode:", w.Code, w.Body.String())
+	}
+}
+
+func TestReturns(t *testing.T) {
+	e := yap.New()
+	e.GET("/p/:id", yap.Returns(func(ctx *yap.Context) (*article, error) {
+		if id := ctx.Param("id"); id != "missing" {
+			return &article{ID: id, Title: "Hello"}, nil
+		}
+		return nil, yap.NewHTTPError(404, "article not found")
+	}))
+	e.POST("/p", yap.Returns(func(ctx *yap.Context) (int, article) {
+		return 201, article{ID: "2", Title: ctx.Param("title")}
+	}))
+	e.GET("/hello", yap.Returns(func(ctx *yap.Context) string {
+		return "hello"
+	}))
+
+	for _, c := range []struct {
+		method, path, accept string
+		code                 int
+		ctype, body          string
+	}{
+		{"GET", "/p/1", "", 200, "application/json", `{"id":"1","title":"Hello"}`},
+		{"GET", "/p/1", "text/html, */*;q=0.8", 200, "application/json", `{"id":"1","title":"Hello"}`},
+		{"GET", "/p/1", "text/html", 406, "text/plain; charset=utf-8", "Not Acceptable"},
+		{"GET", "/p/missing", "application/json", 404, "application/json", `{"error":"article not found"}`},
+		{"GET", "/p/missing", "", 404, "text/plain; charset=utf-8", "article not found"},
+		{"POST", "/p?title=Hi", "", 201, "application/json", `{"id":"2","title":"Hi"}`},
+		{"GET", "/hello", "text/html", 200, "text/plain; charset=utf-8", "hello"},
+	} {
+		w := serveWith(e, c.method, c.path, "Accept", c.accept)
+		if w.Code != c.code || w.Header().Get("Content-Type") != c.ctype || w.Body.String() != c.body {
+			t.Fatal(c.method, c.path, c.accept, w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+	}
+
+	for _, fn := range []any{
+		func(ctx *yap.Context) {}, func() string { return "" }, func(ctx *yap.Context) (string, string) { return "", "" },
+	} {
+		func() {
+			defer func() {
+				if e := recover(); !strings.Contains(fmt.Sprint(e), "Returns:") {
+					t.Fatal("recover:", e)
+				}
+			}()
+			yap.Returns(fn)
+		}()
+	}
+}
+
+type ResultAppV2 struct {
+	yap.AppV2
+}
+
+func (p *ResultAppV2) Main() {
+	yap.XGot_AppV2_Main(p, &getArticle{}, &postArticle{})
+}
+
+type getArticle struct {
+	yap.Handler
+	*ResultAppV2
+}
+
+func (p *getArticle) Result() (any, error) {
+	if id := p.Param("id"); id != "missing" {
+		return &article{ID: id, Title: "Hello"}, nil
+	}
+	return nil, yap.NewHTTPError(404, "article not found")
+}
+
+func (p *getArticle) Main(ctx *yap.Context) {
+	p.Handler.Main(ctx)
+}
+
+func (p *getArticle) Classfname() string {
+	return "get_p_#id"
+}
+
+func (p *getArticle) Classclone() yap.HandlerProto {
+	ret := *p
+	return &ret
+}
+
+type postArticle struct {
+	yap.Handler
+	*ResultAppV2
+}
+
+func (p *postArticle) Result() (int, any) {
+	return 201, "created"
+}
+
+func (p *postArticle) Main(ctx *yap.Context) {
+	p.Handler.Main(ctx)
+}
+
+func (p *postArticle) Classfname() string {
+	return "post_p"
+}
+
+func (p *postArticle) Classclone() yap.HandlerProto {
+	ret := *p
+	return &ret
+}
+
+func TestHandlerResult(t *testing.T) {
+	app := new(ResultAppV2)
+	app.InitYap()
+	app.SetLAS(func(addr string, h http.Handler) error { return nil })
+	app.Main()
+	for _, c := range []struct {
+		method, path, accept string
+		code                 int
+		body                 string
+	}{
+		{"GET", "/p/1", "", 200, `{"id":"1","title":"Hello"}`},
+		{"GET", "/p/1", "text/html", 406, "Not Acceptable"},
+		{"GET", "/p/missing", "application/json", 404, `{"error":"article not found"}`},
+		{"POST", "/p", "", 201, "created"},
+	} {
+		w := serveWith(app, c.method, c.path, "Accept", c.accept)
+		if w.Code != c.code || w.Body.String() != c.body {
+			t.Fatal(c.method, c.path, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// responseWriter wraps http.ResponseWriter, calls registered hooks just
+// before the response header is written, and records the status code and the
+// number of bytes written.
+type responseWriter struct {
+	http.ResponseWriter
+	beforeHeader []func()
+	wroteHeader  bool
+	status       int
+	written      int64
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader && code >= 200 {
+		w.wroteHeader = true
+		w.status = code
+		for _, fn := range w.beforeHeader {
+			fn()
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
+
+// Status returns the status code of the response. It returns 200 if nothing
+// is written.
+func (w *responseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Flush implements the http.Flusher interface.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		f.Flush()
+	}
+}
+
+// Hijack implements the http.Hijacker interface. A hijacked response counts
+// as written.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the original http.ResponseWriter. It is used by
+// http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// beforeWriteHeader registers fn to be called just before the response
+// header of this request is written.
+func (p *Context) beforeWriteHeader(fn func()) {
+	w, ok := p.ResponseWriter.(*responseWriter)
+	if !ok {
+		w = &responseWriter{ResponseWriter: p.ResponseWriter}
+		p.ResponseWriter = w
+	}
+	w.beforeHeader = append(w.beforeHeader, fn)
+}
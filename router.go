@@ -17,8 +17,11 @@
 package yap
 
 import (
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/goplus/yap/internal/url"
 	"github.com/goplus/yap/radix"
@@ -29,7 +32,8 @@ type node = radix.Node[func(ctx *Context)]
 // router is a http rounter which can be used to dispatch requests to different
 // handler functions via configurable routes
 type router struct {
-	trees map[string]*node
+	trees  map[string]*node
+	routes []RouteInfo
 
 	// An optional http.Handler that is called on automatic OPTIONS requests.
 	// The handler is only called if HandleOPTIONS is true and no OPTIONS
@@ -136,6 +140,10 @@ func (p *router) DELETE(path string, handle func(ctx *Context)) {
 // frequently used, non-standardized or custom methods (e.g. for internal
 // communication with a proxy).
 func (p *router) Route(method, path string, handle func(ctx *Context)) {
+	p.route(method, path, handle, nil)
+}
+
+func (p *router) route(method, path string, handle func(ctx *Context), policies []*Policy) {
 	if method == "" {
 		panic("method must not be empty")
 	}
@@ -146,6 +154,12 @@ func (p *router) Route(method, path string, handle func(ctx *Context)) {
 		panic("handle must not be nil")
 	}
 
+	p.tree(method).AddRoute(path, withRoute(path, handle))
+	p.routes = append(p.routes, RouteInfo{Method: method, Path: path, Policies: policies})
+}
+
+// tree returns the routing tree of method, which is created if not exists.
+func (p *router) tree(method string) *node {
 	if p.trees == nil {
 		p.trees = make(map[string]*node)
 	}
@@ -157,8 +171,97 @@ func (p *router) Route(method, path string, handle func(ctx *Context)) {
 
 		p.globalAllowed = p.allowed("*", "")
 	}
+	return root
+}
+
+// static registers a handle serving GET and HEAD requests of paths under
+// prefix, as a fallback of the GET tree (see radix.Node.AddFallback). Unlike
+// a catch-all route, it doesn't conflict with other routes: it is matched
+// only if no other route is matched, and the longest prefix wins. A longer
+// pattern of Engine.Mux takes precedence over it. It returns handle wrapped
+// by withRoute.
+func (p *router) static(prefix string, handle func(ctx *Context), policies []*Policy) func(ctx *Context) {
+	pattern := prefix + "*filepath"
+	handle = withRoute(pattern, handle)
+	p.tree(http.MethodGet).AddFallback(pattern, func(ctx *Context) {
+		if _, muxPattern := ctx.engine.Mux.Handler(ctx.Request); len(muxPattern) > len(prefix) {
+			ctx.engine.Mux.ServeHTTP(ctx.ResponseWriter, ctx.Request)
+			return
+		}
+		handle(ctx)
+	})
+	p.routes = append(p.routes, RouteInfo{Method: http.MethodGet, Path: pattern, Policies: policies})
+	return handle
+}
+
+// RouteInfo represents information of a registered route.
+type RouteInfo struct {
+	// Method is the request method. It is empty for patterns registered to
+	// Engine.Mux by Handle.
+	Method string
+
+	// Path is the route path (or the Mux pattern).
+	Path string
+
+	// Policies are authorization policies attached to the route.
+	Policies []*Policy
+
+	// Name is the route name, eg. "article" of a classfile handler named
+	// get_p_#id@article.yap.
+	Name string
+
+	// Classfname is the file name (without extension) of the classfile
+	// handler of the route, eg. "api/users/get_#id".
+	Classfname string
+}
+
+// String returns the route in the form `METHOD PATH [POLICY ...] @NAME`.
+func (p RouteInfo) String() string {
+	method := p.Method
+	if method == "" {
+		method = "*"
+	}
+	ret := method + " " + p.Path
+	if len(p.Policies) > 0 {
+		names := make([]string, len(p.Policies))
+		for i, policy := range p.Policies {
+			names[i] = policy.Name
+		}
+		ret += " [" + strings.Join(names, " ") + "]"
+	}
+	if p.Name != "" {
+		ret += " @" + p.Name
+	}
+	return ret
+}
 
-	root.AddRoute(path, handle)
+// Routes returns all registered routes in registration order.
+func (p *router) Routes() []RouteInfo {
+	return p.routes
+}
+
+// PrintRoutes prints all registered routes as a table, eg.
+//
+//	METHOD  PATH            NAME  HANDLER                     POLICIES
+//	GET     /api/users/:id  user  api/users/get_#id@user.yap  authenticated
+func (p *router) PrintRoutes(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tNAME\tHANDLER\tPOLICIES")
+	for _, r := range p.routes {
+		method, handler := r.Method, r.Classfname
+		if method == "" {
+			method = "*"
+		}
+		if handler != "" {
+			handler += ".yap"
+		}
+		names := make([]string, len(r.Policies))
+		for i, policy := range r.Policies {
+			names[i] = policy.Name
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", method, r.Path, r.Name, handler, strings.Join(names, " "))
+	}
+	return tw.Flush()
 }
 
 func (p *router) recv(w http.ResponseWriter, req *http.Request) {
@@ -243,7 +346,7 @@ func (p *router) serveHTTP(w http.ResponseWriter, req *http.Request, e *Engine)
 				} else {
 					req.URL.Path = path + "/"
 				}
-				http.Redirect(w, req, req.URL.String(), code)
+				e.redirect(w, req, req.URL.String(), code)
 				return
 			}
 
@@ -255,12 +358,15 @@ func (p *router) serveHTTP(w http.ResponseWriter, req *http.Request, e *Engine)
 				)
 				if found {
 					req.URL.Path = fixedPath
-					http.Redirect(w, req, req.URL.String(), code)
+					e.redirect(w, req, req.URL.String(), code)
 					return
 				}
 			}
 		}
-	} else if req.Method == http.MethodHead {
+	}
+	if req.Method == http.MethodHead {
+		// GET routes (including static files) answer HEAD requests which
+		// aren't routed by HEAD routes
 		p.head(w, req, e)
 		return
 	}
@@ -0,0 +1,191 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// SecureHeadersOptions represents options of the SecureHeaders middleware.
+// A header with a default value is disabled by "-".
+type SecureHeadersOptions struct {
+	// HSTS is the Strict-Transport-Security header sent over HTTPS (default
+	// is "max-age=63072000; includeSubDomains").
+	HSTS string
+
+	ContentTypeOptions string // X-Content-Type-Options header (default is "nosniff")
+	FrameOptions       string // X-Frame-Options header (default is "DENY")
+	ReferrerPolicy     string // Referrer-Policy header (default is "strict-origin-when-cross-origin")
+	PermissionsPolicy  string // Permissions-Policy header, eg. "camera=(), geolocation=()"
+
+	// CSP is the Content-Security-Policy header. "{nonce}" in it is replaced
+	// by a random nonce of each request, eg. "script-src 'self' 'nonce-{nonce}'".
+	// See Context.CSPNonce.
+	CSP string
+
+	// CSPReportOnly sends CSP by the Content-Security-Policy-Report-Only
+	// header, which reports violations without blocking them.
+	CSPReportOnly bool
+
+	// ReportPath is the path of the CSP report endpoint, eg. "/csp-report",
+	// which is added to CSP by the report-uri directive. The endpoint is
+	// registered by UseSecureHeaders.
+	ReportPath string
+
+	// OnReport is called with the body of each CSP report. Reports are logged
+	// by default.
+	OnReport func(r *http.Request, report []byte)
+}
+
+func (p *SecureHeadersOptions) init() {
+	p.HSTS = headerDefault(p.HSTS, "max-age=63072000; includeSubDomains")
+	p.ContentTypeOptions = headerDefault(p.ContentTypeOptions, "nosniff")
+	p.FrameOptions = headerDefault(p.FrameOptions, "DENY")
+	p.ReferrerPolicy = headerDefault(p.ReferrerPolicy, "strict-origin-when-cross-origin")
+	if p.CSP != "" && p.ReportPath != "" && !strings.Contains(p.CSP, "report-uri") {
+		p.CSP = strings.TrimRight(p.CSP, "; ") + "; report-uri " + p.ReportPath
+	}
+}
+
+func headerDefault(v, def string) string {
+	switch v {
+	case "":
+		return def
+	case "-":
+		return ""
+	}
+	return v
+}
+
+type cspNonceKey struct{}
+
+// SecureHeaders returns a middleware which sets security headers of
+// responses (see SecureHeadersOptions). Strict-Transport-Security is sent
+// only if the request is over TLS; use UseSecureHeaders to honour the scheme
+// forwarded by trusted proxies.
+func SecureHeaders(opts ...SecureHeadersOptions) func(h http.Handler) http.Handler {
+	var o SecureHeadersOptions
+	if opts != nil {
+		o = opts[0]
+	}
+	o.init()
+	return secureHeaders(o, func(r *http.Request) bool {
+		return r.TLS != nil
+	})
+}
+
+func secureHeaders(o SecureHeadersOptions, isHTTPS func(r *http.Request) bool) func(h http.Handler) http.Handler {
+	cspHeader := "Content-Security-Policy"
+	if o.CSPReportOnly {
+		cspHeader = "Content-Security-Policy-Report-Only"
+	}
+	withNonce := strings.Contains(o.CSP, "{nonce}")
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			if o.HSTS != "" && isHTTPS(r) {
+				header.Set("Strict-Transport-Security", o.HSTS)
+			}
+			setHeaderIf(header, "X-Content-Type-Options", o.ContentTypeOptions)
+			setHeaderIf(header, "X-Frame-Options", o.FrameOptions)
+			setHeaderIf(header, "Referrer-Policy", o.ReferrerPolicy)
+			setHeaderIf(header, "Permissions-Policy", o.PermissionsPolicy)
+			if withNonce {
+				nonce := newCSPNonce()
+				header.Set(cspHeader, strings.ReplaceAll(o.CSP, "{nonce}", nonce))
+				r = r.WithContext(context.WithValue(r.Context(), cspNonceKey{}, nonce))
+				if ct, ok := r.Context().Value(cacheTagsKey{}).(*cacheTags); ok {
+					ct.nonce = true // the response of a nonce is not cacheable
+				}
+			} else {
+				setHeaderIf(header, cspHeader, o.CSP)
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+func setHeaderIf(h http.Header, key, val string) {
+	if val != "" {
+		h.Set(key, val)
+	}
+}
+
+func newCSPNonce() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		log.Panicln("SecureHeaders:", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b[:]) // no chars escaped in templates
+}
+
+// UseSecureHeaders adds the SecureHeaders middleware to the engine, which
+// applies to all requests including static files. It also adds a template
+// function `cspNonce` returning the CSP nonce, eg.
+//
+//	<script nonce="{{cspNonce}}">...</script>
+//
+// If ReportPath is set, it registers the CSP report endpoint by POST.
+func (p *Engine) UseSecureHeaders(opts ...SecureHeadersOptions) {
+	var o SecureHeadersOptions
+	if opts != nil {
+		o = opts[0]
+	}
+	o.init()
+	p.Use(secureHeaders(o, func(r *http.Request) bool {
+		return resolveRemote(r, p.trusted).scheme == "https"
+	}))
+	p.ctxFunc("cspNonce", func(ctx *Context) any {
+		return func() string {
+			return ctx.CSPNonce()
+		}
+	})
+	if o.ReportPath != "" {
+		onReport := o.OnReport
+		if onReport == nil {
+			onReport = func(r *http.Request, report []byte) {
+				log.Println("yap: CSP violation:", strings.TrimSpace(string(report)))
+			}
+		}
+		p.POST(o.ReportPath, func(ctx *Context) {
+			report, err := io.ReadAll(io.LimitReader(ctx.Body, 64<<10))
+			if err != nil || len(report) == 0 {
+				ctx.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			onReport(ctx.Request, report)
+			ctx.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// CSPNonce returns the CSP nonce of the request. It returns an empty string if
+// the SecureHeaders middleware is not used, or the CSP has no nonce.
+func (p *Context) CSPNonce() string {
+	return cspNonce(p.Request)
+}
+
+func cspNonce(r *http.Request) string {
+	nonce, _ := r.Context().Value(cspNonceKey{}).(string)
+	return nonce
+}
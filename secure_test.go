@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/goplus/yap"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	var reports []string
+	e := yap.New(fstest.MapFS{
+		"page_yap.html": {Data: []byte(`<script nonce="{{cspNonce}}">go()</script>`)},
+		"static/app.js": {Data: []byte(`go()`)},
+	})
+	e.SetTrustedProxies("10.0.0.1")
+	e.UseSecureHeaders(yap.SecureHeadersOptions{
+		FrameOptions:      "-",
+		PermissionsPolicy: "camera=()",
+		CSP:               "default-src 'self'; script-src 'self' 'nonce-{nonce}'",
+		ReportPath:        "/csp-report",
+		OnReport: func(r *http.Request, report []byte) {
+			reports = append(reports, string(report))
+		},
+	})
+	e.Static("/static")
+	e.GET("/page", func(ctx *yap.Context) {
+		ctx.YAP(200, "page", nil)
+	})
+
+	w := serveWith(e, "GET", "/page")
+	h := w.Header()
+	if h.Get("X-Content-Type-Options") != "nosniff" || h.Get("Referrer-Policy") != "strict-origin-when-cross-origin" ||
+		h.Get("Permissions-Policy") != "camera=()" || h.Get("X-Frame-Options") != "" || h.Get("Strict-Transport-Security") != "" {
+		t.Fatal("SecureHeaders:", h)
+	}
+	nonce := strings.TrimSuffix(strings.TrimPrefix(w.Body.String(), `<script nonce="`), `">go()</script>`)
+	csp := "default-src 'self'; script-src 'self' 'nonce-" + nonce + "'; report-uri /csp-report"
+	if nonce == "" || h.Get("Content-Security-Policy") != csp {
+		t.Fatal("CSP:", h.Get("Content-Security-Policy"), w.Body.String())
+	}
+	if w2 := serveWith(e, "GET", "/page"); strings.Contains(w2.Body.String(), nonce) {
+		t.Fatal("CSP: nonce reused")
+	}
+
+	w = serveWith(remoteHandler(e, "10.0.0.1:1000"), "GET", "/static/app.js", "X-Forwarded-Proto", "https")
+	if w.Code != 200 || w.Header().Get("Strict-Transport-Security") != "max-age=63072000; includeSubDomains" ||
+		w.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Fatal("SecureHeaders: static", w.Code, w.Header())
+	}
+
+	req := httptest.NewRequest("POST", "/csp-report", strings.NewReader(`{"csp-report":{}}`))
+	req.Header.Set("Content-Type", "application/csp-report")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent || len(reports) != 1 || reports[0] != `{"csp-report":{}}` {
+		t.Fatal("CSP report:", w.Code, reports)
+	}
+}
+
+func TestSecureHeadersMiddleware(t *testing.T) {
+	h := yap.SecureHeaders(yap.SecureHeadersOptions{CSP: "default-src 'self'", CSPReportOnly: true})(http.NotFoundHandler())
+	w := serveWith(h, "GET", "/")
+	if w.Header().Get("Content-Security-Policy-Report-Only") != "default-src 'self'" ||
+		w.Header().Get("X-Frame-Options") != "DENY" || w.Header().Get("Content-Security-Policy") != "" {
+		t.Fatal("SecureHeaders:", w.Header())
+	}
+}
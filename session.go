@@ -0,0 +1,473 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SessionStore represents a session storage backend.
+//
+// A store maps a session cookie value to session data. For server-side
+// stores the cookie value is a session id, and for the cookie store it is the
+// session data itself.
+type SessionStore interface {
+	// Load returns session data associated with the session cookie value.
+	// It returns (nil, nil) if the session doesn't exist or is expired.
+	Load(cookie string) ([]byte, error)
+
+	// Save saves session data and returns the session cookie value to send.
+	// cookie is empty if it is a new session.
+	Save(cookie string, data []byte, maxAge time.Duration) (string, error)
+
+	// Delete removes the session associated with the session cookie value.
+	Delete(cookie string) error
+}
+
+// ContextSessionStore is a SessionStore which takes the request context, so
+// its operations are cancelled with the request (eg. ydb.SessionStore). If a
+// store implements it, these methods are called instead of those of
+// SessionStore.
+type ContextSessionStore interface {
+	SessionStore
+	LoadContext(ctx context.Context, cookie string) ([]byte, error)
+	SaveContext(ctx context.Context, cookie string, data []byte, maxAge time.Duration) (string, error)
+	DeleteContext(ctx context.Context, cookie string) error
+}
+
+// SessionOptions represents options of sessions.
+type SessionOptions struct {
+	Name     string        // cookie name (default is "yap_session")
+	Path     string        // cookie path (default is "/")
+	Domain   string        // cookie domain
+	MaxAge   time.Duration // session lifetime (default is 24 hours)
+	Secure   bool          // send cookie only over HTTPS
+	SameSite http.SameSite // SameSite attribute of the cookie
+}
+
+const (
+	defaultSessionName   = "yap_session"
+	defaultSessionMaxAge = 24 * time.Hour
+)
+
+type sessionMgr struct {
+	store SessionStore
+	SessionOptions
+}
+
+// UseSession enables sessions by specified store. After calling it, use
+// Context.Session to access the session of a request, and use the template
+// function `flashes` to get flash messages in YAP templates.
+func (p *Engine) UseSession(store SessionStore, opts ...SessionOptions) {
+	mgr := &sessionMgr{store: store}
+	if opts != nil {
+		mgr.SessionOptions = opts[0]
+	}
+	if mgr.Name == "" {
+		mgr.Name = defaultSessionName
+	}
+	if mgr.Path == "" {
+		mgr.Path = "/"
+	}
+	if mgr.MaxAge <= 0 {
+		mgr.MaxAge = defaultSessionMaxAge
+	}
+	p.sess = mgr
+	p.ctxFunc("flashes", func(ctx *Context) any {
+		return func() []Flash {
+			return ctx.Flashes()
+		}
+	})
+}
+
+func (p *sessionMgr) load(ctx *Context) *Session {
+	s := new(Session)
+	if c, err := ctx.Request.Cookie(p.Name); err == nil {
+		b, err := p.loadData(ctx, c.Value)
+		if err != nil {
+			log.Println("yap: load session:", err)
+		} else if b != nil && json.Unmarshal(b, &s.data) == nil {
+			s.cookie = c.Value
+		}
+	}
+	saved := false
+	s.save = func() {
+		if !saved {
+			saved = true
+			p.save(ctx, s)
+		}
+	}
+	ctx.beforeWriteHeader(s.save)
+	return s
+}
+
+func (p *sessionMgr) save(ctx *Context, s *Session) {
+	if s.destroyed {
+		if s.cookie != "" {
+			if err := p.deleteData(ctx, s.cookie); err != nil {
+				log.Println("yap: delete session:", err)
+			}
+			p.setCookie(ctx, "", -1)
+		}
+		return
+	}
+	if !s.dirty {
+		return
+	}
+	if s.renew && s.cookie != "" {
+		if err := p.deleteData(ctx, s.cookie); err != nil {
+			log.Println("yap: delete session:", err)
+		}
+		s.cookie = ""
+	}
+	b, err := json.Marshal(&s.data)
+	if err == nil {
+		s.cookie, err = p.saveData(ctx, s.cookie, b)
+	}
+	if err != nil {
+		log.Println("yap: save session:", err)
+		return
+	}
+	p.setCookie(ctx, s.cookie, int(p.MaxAge/time.Second))
+}
+
+func (p *sessionMgr) loadData(ctx *Context, cookie string) ([]byte, error) {
+	if store, ok := p.store.(ContextSessionStore); ok {
+		return store.LoadContext(ctx, cookie)
+	}
+	return p.store.Load(cookie)
+}
+
+func (p *sessionMgr) saveData(ctx *Context, cookie string, data []byte) (string, error) {
+	if store, ok := p.store.(ContextSessionStore); ok {
+		return store.SaveContext(ctx, cookie, data, p.MaxAge)
+	}
+	return p.store.Save(cookie, data, p.MaxAge)
+}
+
+func (p *sessionMgr) deleteData(ctx *Context, cookie string) error {
+	if store, ok := p.store.(ContextSessionStore); ok {
+		return store.DeleteContext(ctx, cookie)
+	}
+	return p.store.Delete(cookie)
+}
+
+func (p *sessionMgr) setCookie(ctx *Context, val string, maxAge int) {
+	http.SetCookie(ctx.ResponseWriter, &http.Cookie{
+		Name:     p.Name,
+		Value:    val,
+		Path:     p.Path,
+		Domain:   p.Domain,
+		MaxAge:   maxAge,
+		Secure:   p.Secure,
+		HttpOnly: true,
+		SameSite: p.SameSite,
+	})
+}
+
+// -----------------------------------------------------------------------------
+
+// Flash represents a flash message, which is stored in the session and is
+// removed once it is read.
+type Flash struct {
+	Kind    string `json:"k,omitempty"`
+	Message string `json:"m"`
+}
+
+type sessionData struct {
+	Values  map[string]any `json:"v,omitempty"`
+	Flashes []Flash        `json:"f,omitempty"`
+}
+
+// Session represents a user session. Session values are encoded in json, so
+// a number value is read back as float64.
+//
+// Changes of a session are saved automatically just before the response
+// header is written.
+type Session struct {
+	data      sessionData
+	cookie    string
+	dirty     bool
+	renew     bool
+	destroyed bool
+	save      func() // saves the session once, see sessionMgr.load
+}
+
+// Get returns the session value associated with key.
+func (p *Session) Get(key string) any {
+	return p.data.Values[key]
+}
+
+// Set sets the session value associated with key.
+func (p *Session) Set(key string, val any) {
+	if p.data.Values == nil {
+		p.data.Values = make(map[string]any)
+	}
+	p.data.Values[key] = val
+	p.dirty = true
+}
+
+// Delete deletes the session value associated with key.
+func (p *Session) Delete(key string) {
+	if _, ok := p.data.Values[key]; ok {
+		delete(p.data.Values, key)
+		p.dirty = true
+	}
+}
+
+// Clear deletes all session values and flash messages.
+func (p *Session) Clear() {
+	p.data = sessionData{}
+	p.dirty = true
+}
+
+// IsNew reports whether the session is created by this request.
+func (p *Session) IsNew() bool {
+	return p.cookie == ""
+}
+
+// Renew keeps session values but changes the session id. It should be called
+// when the privilege level changes (eg. after login) to avoid session fixation.
+func (p *Session) Renew() {
+	p.renew, p.dirty = true, true
+}
+
+// Destroy removes the session from the store and the client.
+func (p *Session) Destroy() {
+	p.data = sessionData{}
+	p.destroyed = true
+}
+
+// Session returns the session of the request. It panics if sessions are not
+// enabled (see Engine.UseSession).
+func (p *Context) Session() *Session {
+	ref := p.sessionRef()
+	if ref.s == nil {
+		mgr := p.engine.sess
+		if mgr == nil {
+			log.Panicln("yap: session not enabled, please call UseSession first")
+		}
+		ref.s = mgr.load(p)
+	}
+	return ref.s
+}
+
+// sessionRef refers to the session of a request. It is created before a route
+// is handled (see withRoute), so copies of the Context (eg. a _middleware
+// handler and the handler it runs before) load the same session.
+type sessionRef struct {
+	s *Session
+}
+
+func (p *Context) sessionRef() *sessionRef {
+	if p.sess == nil {
+		p.sess = new(sessionRef)
+	}
+	return p.sess
+}
+
+// saveSession saves the session at the end of the route if the handler didn't
+// write the header (net/http writes it after the route then).
+func (p *Context) saveSession() {
+	if p.sess != nil && p.sess.s != nil {
+		p.sess.s.save()
+	}
+}
+
+// AddFlash adds a flash message to the session. Flash messages survive until
+// they are read, eg. across one redirect.
+func (p *Context) AddFlash(kind, msg string) {
+	s := p.Session()
+	s.data.Flashes = append(s.data.Flashes, Flash{Kind: kind, Message: msg})
+	s.dirty = true
+}
+
+// Flashes returns flash messages of the session and removes them.
+func (p *Context) Flashes() []Flash {
+	s := p.Session()
+	ret := s.data.Flashes
+	if ret != nil {
+		s.data.Flashes = nil
+		s.dirty = true
+	}
+	return ret
+}
+
+// -----------------------------------------------------------------------------
+
+var (
+	// ErrSessionTooLarge is returned by CookieStore if session data doesn't fit
+	// in a cookie.
+	ErrSessionTooLarge = errors.New("yap: session too large for cookie store")
+)
+
+const maxCookieSize = 4096
+
+// CookieStore is a SessionStore which keeps session data in the cookie
+// itself. Session data is signed (or encrypted if Encrypt is true) by a
+// CookieCodec, so it can't be modified by clients.
+type CookieStore struct {
+	codec *CookieCodec
+
+	// Encrypt specifies whether session data is encrypted. Otherwise session
+	// data is only signed and it is readable by clients.
+	Encrypt bool
+}
+
+// NewCookieStore creates a CookieStore by specified codec.
+func NewCookieStore(codec *CookieCodec) *CookieStore {
+	return &CookieStore{codec: codec}
+}
+
+const cookieStoreName = "yap session"
+
+// Load implements SessionStore.Load.
+func (p *CookieStore) Load(cookie string) ([]byte, error) {
+	var b []byte
+	var err error
+	if p.Encrypt {
+		b, err = p.codec.Decrypt(cookieStoreName, cookie, 0)
+	} else {
+		b, err = p.codec.Verify(cookieStoreName, cookie, 0)
+	}
+	if err != nil || len(b) < 8 {
+		return nil, nil // invalid session cookie means no session
+	}
+	expire := time.Unix(int64(binary.BigEndian.Uint64(b)), 0)
+	if p.codec.now().After(expire) {
+		return nil, nil
+	}
+	return b[8:], nil
+}
+
+// Save implements SessionStore.Save.
+func (p *CookieStore) Save(cookie string, data []byte, maxAge time.Duration) (string, error) {
+	b := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint64(b, uint64(p.codec.now().Add(maxAge).Unix()))
+	b = append(b, data...)
+	var ret string
+	if p.Encrypt {
+		ret = p.codec.Encrypt(cookieStoreName, b)
+	} else {
+		ret = p.codec.Sign(cookieStoreName, b)
+	}
+	if len(ret) > maxCookieSize {
+		return "", ErrSessionTooLarge
+	}
+	return ret, nil
+}
+
+// Delete implements SessionStore.Delete.
+func (p *CookieStore) Delete(cookie string) error {
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+type memSession struct {
+	data   []byte
+	expire time.Time
+}
+
+// MemoryStore is an in-memory SessionStore. Sessions expire after maxAge
+// and expired sessions are removed periodically.
+type MemoryStore struct {
+	sessions map[string]memSession
+	lastGC   time.Time
+	now      func() time.Time
+	mutex    sync.Mutex
+}
+
+// NewMemoryStore creates a MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]memSession),
+		lastGC:   time.Now(),
+		now:      time.Now,
+	}
+}
+
+// Load implements SessionStore.Load.
+func (p *MemoryStore) Load(id string) ([]byte, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	s, ok := p.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	if p.now().After(s.expire) {
+		delete(p.sessions, id)
+		return nil, nil
+	}
+	return s.data, nil
+}
+
+// Save implements SessionStore.Save.
+func (p *MemoryStore) Save(id string, data []byte, maxAge time.Duration) (string, error) {
+	if id == "" {
+		id = NewSessionID()
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	now := p.now()
+	p.sessions[id] = memSession{data: data, expire: now.Add(maxAge)}
+	if now.Sub(p.lastGC) > time.Minute {
+		p.lastGC = now
+		for k, s := range p.sessions {
+			if now.After(s.expire) {
+				delete(p.sessions, k)
+			}
+		}
+	}
+	return id, nil
+}
+
+// Delete implements SessionStore.Delete.
+func (p *MemoryStore) Delete(id string) error {
+	p.mutex.Lock()
+	delete(p.sessions, id)
+	p.mutex.Unlock()
+	return nil
+}
+
+// Len returns the number of sessions in the store (including expired ones
+// not removed yet).
+func (p *MemoryStore) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.sessions)
+}
+
+// NewSessionID returns a new random session id.
+func NewSessionID() string {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		log.Panicln("NewSessionID:", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,209 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/goplus/yap"
+)
+
+// serve sends a request to e and returns the response recorder.
+func serve(e http.Handler, method, path string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func respCookie(w *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, c := range w.Result().Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestCookieCodec(t *testing.T) {
+	old := yap.NewCookieCodec([]byte("old-key"))
+	codec := yap.NewCookieCodec([]byte("new-key"), []byte("old-key"))
+
+	signed := old.Sign("c", []byte("hello"))
+	if v, err := codec.Verify("c", signed, 0); err != nil || string(v) != "hello" {
+		t.Fatal("Verify with rotated key:", string(v), err)
+	}
+	if _, err := codec.Verify("d", signed, 0); err != yap.ErrInvalidCookie {
+		t.Fatal("Verify with another name:", err)
+	}
+	if _, err := codec.Verify("c", signed[:len(signed)-2]+"AA", 0); err != yap.ErrInvalidCookie {
+		t.Fatal("Verify tampered:", err)
+	}
+
+	encrypted := old.Encrypt("c", []byte("secret"))
+	if strings.Contains(encrypted, "secret") {
+		t.Fatal("Encrypt: plaintext leaked")
+	}
+	if v, err := codec.Decrypt("c", encrypted, time.Hour); err != nil || string(v) != "secret" {
+		t.Fatal("Decrypt with rotated key:", string(v), err)
+	}
+	if _, err := yap.NewCookieCodec([]byte("other")).Decrypt("c", encrypted, 0); err != yap.ErrInvalidCookie {
+		t.Fatal("Decrypt with unknown key:", err)
+	}
+}
+
+func TestSignedCookie(t *testing.T) {
+	e := newEngine()
+	e.SetCookieKeys([]byte("key"))
+	e.GET("/set", func(ctx *yap.Context) {
+		ctx.SetSignedCookie(&http.Cookie{Name: "user", Value: "alice"})
+		ctx.SetSecureCookie(&http.Cookie{Name: "token", Value: "t0ken"})
+		ctx.TEXT(200, "text/plain", "ok")
+	})
+	e.GET("/get", func(ctx *yap.Context) {
+		user, err1 := ctx.SignedCookie("user")
+		token, err2 := ctx.SecureCookie("token", time.Minute)
+		if err1 != nil || err2 != nil {
+			ctx.TEXT(400, "text/plain", "invalid")
+			return
+		}
+		ctx.TEXT(200, "text/plain", user+" "+token)
+	})
+	w := serve(e, "GET", "/set")
+	user, token := respCookie(w, "user"), respCookie(w, "token")
+	if w = serve(e, "GET", "/get", user, token); w.Body.String() != "alice t0ken" {
+		t.Fatal("signed cookie:", w.Body.String())
+	}
+	user.Value = "alice"
+	if w = serve(e, "GET", "/get", user, token); w.Code != 400 {
+		t.Fatal("unsigned cookie accepted:", w.Code)
+	}
+}
+
+func sessionEngine(store yap.SessionStore) *yap.Engine {
+	e := yap.New(fstest.MapFS{
+		"flash_yap.html": {Data: []byte(`{{range flashes}}[{{.Kind}}:{{.Message}}]{{end}}`)},
+	})
+	e.UseSession(store)
+	e.GET("/login", func(ctx *yap.Context) {
+		sess := ctx.Session()
+		sess.Set("user", ctx.Param("user"))
+		sess.Renew()
+		ctx.AddFlash("info", "welcome")
+		ctx.Redirect("/home")
+	})
+	e.GET("/home", func(ctx *yap.Context) {
+		ctx.YAP(200, "flash", nil)
+	})
+	e.GET("/me", func(ctx *yap.Context) {
+		user, _ := ctx.Session().Get("user").(string)
+		ctx.TEXT(200, "text/plain", user)
+	})
+	e.GET("/logout", func(ctx *yap.Context) {
+		ctx.Session().Destroy()
+		ctx.TEXT(200, "text/plain", "bye")
+	})
+	return e
+}
+
+func testSession(t *testing.T, store yap.SessionStore) {
+	e := sessionEngine(store)
+	w := serve(e, "GET", "/login?user=bob")
+	if w.Code != http.StatusFound {
+		t.Fatal("login:", w.Code)
+	}
+	c := respCookie(w, "yap_session")
+	if c == nil || !c.HttpOnly {
+		t.Fatal("login: no session cookie")
+	}
+	if w = serve(e, "GET", "/home", c); w.Body.String() != "[info:welcome]" {
+		t.Fatal("flash after redirect:", w.Body.String())
+	}
+	if c2 := respCookie(w, "yap_session"); c2 != nil {
+		c = c2 // flashes are consumed
+	}
+	if w = serve(e, "GET", "/home", c); w.Body.String() != "" {
+		t.Fatal("flash should be read only once:", w.Body.String())
+	}
+	if w = serve(e, "GET", "/me", c); w.Body.String() != "bob" {
+		t.Fatal("session value:", w.Body.String())
+	}
+	w = serve(e, "GET", "/logout", c)
+	if c2 := respCookie(w, "yap_session"); c2 == nil || c2.MaxAge >= 0 {
+		t.Fatal("logout: session cookie not removed")
+	}
+}
+
+func TestSessionMemoryStore(t *testing.T) {
+	store := yap.NewMemoryStore()
+	testSession(t, store)
+	if n := store.Len(); n != 0 {
+		t.Fatal("MemoryStore: sessions left after logout:", n)
+	}
+}
+
+func TestSessionMemoryStoreTTL(t *testing.T) {
+	store := yap.NewMemoryStore()
+	id, _ := store.Save("", []byte("{}"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if data, _ := store.Load(id); data != nil {
+		t.Fatal("MemoryStore: session not expired")
+	}
+}
+
+func TestSessionCookieStore(t *testing.T) {
+	store := yap.NewCookieStore(yap.NewCookieCodec([]byte("key")))
+	testSession(t, store)
+	store.Encrypt = true
+	testSession(t, store)
+	if _, err := store.Save("", make([]byte, 8192), time.Hour); err != yap.ErrSessionTooLarge {
+		t.Fatal("CookieStore: large session:", err)
+	}
+}
+
+func TestSessionImplicitWrite(t *testing.T) {
+	e := sessionEngine(yap.NewMemoryStore())
+	e.GET("/remember", func(ctx *yap.Context) {
+		ctx.Session().Set("user", ctx.Param("user")) // writes nothing
+	})
+	w := serve(e, "GET", "/remember?user=alice")
+	c := respCookie(w, "yap_session")
+	if w.Code != 200 || c == nil {
+		t.Fatal("remember: no session cookie:", w.Code)
+	}
+	if w = serve(e, "GET", "/me", c); w.Body.String() != "alice" {
+		t.Fatal("session value:", w.Body.String())
+	}
+}
+
+func TestSessionNotEnabled(t *testing.T) {
+	_, _, ctx := newContext("GET", "/", nil)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Session should panic if not enabled")
+		}
+	}()
+	ctx.Session()
+}
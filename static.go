@@ -0,0 +1,247 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+
+// StaticOptions represents options of StaticWith.
+type StaticOptions struct {
+	// Index is the file served for a directory. It is "index.html" by default,
+	// and "-" means no index file.
+	Index string
+
+	// Browse enables directory listing of directories without an index file.
+	Browse bool
+
+	// SPAFallback is the file (eg. "index.html") served for unknown paths
+	// without a file extension, so client-side routes of a single-page app
+	// work on reload.
+	SPAFallback string
+
+	// NotFound is the file (eg. "404.html") served with 404 Not Found for
+	// unknown paths.
+	NotFound string
+
+	// CacheControl is the Cache-Control header of files by extension (eg.
+	// ".css"). The "" key is for other files.
+	CacheControl map[string]string
+
+	// Immutable enables "Cache-Control: public, max-age=31536000, immutable"
+	// for files whose names contain a content hash, eg. "app.3f9a0c1e.js".
+	Immutable bool
+
+	// AllowHidden allows files and directories whose names start with ".".
+	// They are denied with 404 Not Found by default, except ".well-known".
+	AllowHidden bool
+}
+
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// StaticWith serves static files from fsys with options. See StaticOptions.
+// For example, to serve a single-page app:
+//
+//	y.StaticWith("/", http.FS(dist), yap.StaticOptions{
+//		SPAFallback: "index.html",
+//		Immutable:   true,
+//	})
+func (p *Engine) StaticWith(pattern string, fsys http.FileSystem, opts StaticOptions) {
+	p.Group("").StaticWith(pattern, fsys, opts)
+}
+
+// StaticWith serves static files from fsys with options, under the group
+// prefix. Middlewares and policies of the group apply.
+func (p *Group) StaticWith(pattern string, fsys http.FileSystem, opts StaticOptions) {
+	pattern = p.prefix + pattern
+	if !strings.HasSuffix(pattern, "/") {
+		pattern += "/"
+	}
+	if opts.Index == "" {
+		opts.Index = "index.html"
+	}
+	s := &staticServer{fsys: fsys, opts: opts}
+	if opts.Browse {
+		s.browse = http.StripPrefix(pattern, http.FileServer(fsys))
+	}
+	p.engine.static(pattern, p.wrap(func(ctx *Context) {
+		s.serve(ctx, "/"+strings.TrimPrefix(ctx.URL.Path, pattern))
+	}), p.policies)
+}
+
+type staticServer struct {
+	fsys   http.FileSystem
+	opts   StaticOptions
+	browse http.Handler
+}
+
+func (p *staticServer) serve(ctx *Context, name string) {
+	name = path.Clean(name)
+	if !p.opts.AllowHidden && isHiddenPath(name) {
+		p.notFound(ctx)
+		return
+	}
+	f, err := p.fsys.Open(name)
+	if err != nil {
+		p.openFailed(ctx, name, err)
+		return
+	}
+	defer f.Close()
+	d, err := f.Stat()
+	if err != nil {
+		p.openFailed(ctx, name, err)
+		return
+	}
+	if d.IsDir() {
+		urlPath := ctx.URL.Path
+		if !strings.HasSuffix(urlPath, "/") {
+			http.Redirect(ctx.ResponseWriter, ctx.Request, path.Base(urlPath)+"/", http.StatusMovedPermanently)
+			return
+		}
+		if p.opts.Index != "-" {
+			if p.serveFile(ctx, path.Join(name, p.opts.Index), http.StatusOK) {
+				return
+			}
+		}
+		if p.browse != nil {
+			p.browse.ServeHTTP(ctx.ResponseWriter, ctx.Request)
+			return
+		}
+		p.notFound(ctx)
+		return
+	}
+	p.setCacheControl(ctx, name)
+	http.ServeContent(ctx.ResponseWriter, ctx.Request, d.Name(), d.ModTime(), f)
+}
+
+func (p *staticServer) openFailed(ctx *Context, name string, err error) {
+	if !errors.Is(err, fs.ErrNotExist) {
+		msg, code := "500 Internal Server Error", http.StatusInternalServerError
+		if errors.Is(err, fs.ErrPermission) {
+			msg, code = "403 Forbidden", http.StatusForbidden
+		}
+		http.Error(ctx.ResponseWriter, msg, code)
+		return
+	}
+	if fallback := p.opts.SPAFallback; fallback != "" && path.Ext(name) == "" {
+		ctx.ResponseWriter.Header().Set("Cache-Control", "no-cache")
+		if p.serveFile(ctx, "/"+fallback, http.StatusOK) {
+			return
+		}
+	}
+	p.notFound(ctx)
+}
+
+func (p *staticServer) notFound(ctx *Context) {
+	if p.opts.NotFound == "" || !p.serveFile(ctx, "/"+p.opts.NotFound, http.StatusNotFound) {
+		http.Error(ctx.ResponseWriter, "404 page not found", http.StatusNotFound)
+	}
+}
+
+// serveFile serves the file name with the status code, and reports whether the
+// file exists.
+func (p *staticServer) serveFile(ctx *Context, name string, code int) bool {
+	f, err := p.fsys.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	d, err := f.Stat()
+	if err != nil || d.IsDir() {
+		return false
+	}
+	if code == http.StatusOK {
+		http.ServeContent(ctx.ResponseWriter, ctx.Request, d.Name(), d.ModTime(), f)
+		return true
+	}
+	h := ctx.ResponseWriter.Header()
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		h.Set("Content-Type", ctype)
+	}
+	h.Set("Cache-Control", "no-cache")
+	ctx.ResponseWriter.WriteHeader(code)
+	io.Copy(ctx.ResponseWriter, f)
+	return true
+}
+
+func (p *staticServer) setCacheControl(ctx *Context, name string) {
+	h := ctx.ResponseWriter.Header()
+	if h.Get("Cache-Control") != "" {
+		return
+	}
+	if p.opts.Immutable && isHashedName(path.Base(name)) {
+		h.Set("Cache-Control", immutableCacheControl)
+		return
+	}
+	cc, ok := p.opts.CacheControl[strings.ToLower(path.Ext(name))]
+	if !ok {
+		cc = p.opts.CacheControl[""]
+	}
+	if cc != "" {
+		h.Set("Cache-Control", cc)
+	}
+}
+
+func isHiddenPath(name string) bool {
+	for _, elem := range strings.Split(name, "/") {
+		if strings.HasPrefix(elem, ".") && elem != ".well-known" && elem != "." {
+			return true
+		}
+	}
+	return false
+}
+
+// isHashedName reports whether a file name contains a content hash, that is, a
+// part of at least 8 hex digits (with at least one decimal digit) separated by
+// "." or "-" before the extension, eg. "app.3f9a0c1e.js". Words such as
+// "logo-header2x.png" aren't hashes.
+func isHashedName(name string) bool {
+	ext := path.Ext(name)
+	if ext == "" {
+		return false
+	}
+	base := name[:len(name)-len(ext)]
+	i := strings.LastIndexAny(base, ".-")
+	if i < 0 {
+		return false
+	}
+	hash := base[i+1:]
+	if len(hash) < 8 {
+		return false
+	}
+	digit := false
+	for _, c := range hash {
+		switch {
+		case '0' <= c && c <= '9':
+			digit = true
+		case 'a' <= c && c <= 'f', 'A' <= c && c <= 'F':
+		default:
+			return false
+		}
+	}
+	return digit
+}
+
+// -----------------------------------------------------------------------------
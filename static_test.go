@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/goplus/yap"
+)
+
+var distFS = fstest.MapFS{
+	"index.html":              {Data: []byte("<app>")},
+	"404.html":                {Data: []byte("<missing>")},
+	"style.css":               {Data: []byte("body{}")},
+	"assets/app.3f9a0c1e.js":  {Data: []byte("app()")},
+	"assets/overview.js":      {Data: []byte("overview()")},
+	"assets/logo-header2x.js": {Data: []byte("logo()")},
+	"docs/a.txt":              {Data: []byte("a")},
+	".env":                    {Data: []byte("SECRET=1")},
+	".well-known/change-pass": {Data: []byte("change")},
+}
+
+func TestStaticWith(t *testing.T) {
+	e := yap.New()
+	e.GET("/api/ping", func(ctx *yap.Context) {
+		ctx.TEXT(200, "text/plain", "pong")
+	})
+	e.Handle("/mux/", func(ctx *yap.Context) {
+		ctx.TEXT(200, "text/plain", "mux")
+	})
+	e.StaticWith("/", http.FS(distFS), yap.StaticOptions{
+		SPAFallback:  "index.html",
+		NotFound:     "404.html",
+		CacheControl: map[string]string{".css": "public, max-age=3600", "": "no-cache"},
+		Immutable:    true,
+	})
+
+	for _, c := range []struct {
+		path, body, cacheControl string
+		code                     int
+	}{
+		{"/", "<app>", "", 200},
+		{"/settings/profile", "<app>", "no-cache", 200},
+		{"/missing.js", "<missing>", "no-cache", 404},
+		{"/style.css", "body{}", "public, max-age=3600", 200},
+		{"/assets/app.3f9a0c1e.js", "app()", "public, max-age=31536000, immutable", 200},
+		{"/assets/overview.js", "overview()", "no-cache", 200},
+		{"/assets/logo-header2x.js", "logo()", "no-cache", 200},
+		{"/.env", "<missing>", "no-cache", 404},
+		{"/.well-known/change-pass", "change", "no-cache", 200},
+		{"/docs/", "<missing>", "no-cache", 404},
+		{"/api/ping", "pong", "", 200},
+		{"/mux/x", "mux", "", 200},
+	} {
+		w := serveWith(e, "GET", c.path)
+		if w.Code != c.code || w.Body.String() != c.body || w.Header().Get("Cache-Control") != c.cacheControl {
+			t.Fatal(c.path, w.Code, w.Body.String(), w.Header().Get("Cache-Control"))
+		}
+	}
+	w := serveWith(e, "POST", "/style.css")
+	if w.Code != 405 || !strings.Contains(w.Header().Get("Allow"), "GET") {
+		t.Fatal("POST:", w.Code, w.Header().Get("Allow"))
+	}
+	if w = serveWith(e, "HEAD", "/style.css"); w.Code != 200 || w.Body.Len() != 0 {
+		t.Fatal("HEAD:", w.Code, w.Body.String())
+	}
+	found := false
+	for _, r := range e.Routes() {
+		found = found || r.String() == "GET /*filepath"
+	}
+	if !found {
+		t.Fatal("Routes:", e.Routes())
+	}
+}
+
+func TestStaticGroup(t *testing.T) {
+	e := yap.New()
+	e.Use(yap.Auth(yap.AuthOptions{}, users))
+	g := e.Group("/files", yap.RequireAuth())
+	g.StaticWith("/", http.FS(distFS), yap.StaticOptions{Browse: true, Index: "-"})
+
+	if code := serveAs(e, "GET", "/files/style.css", ""); code != 401 {
+		t.Fatal("anonymous:", code)
+	}
+	if code := serveAs(e, "GET", "/files/style.css", "reader"); code != 200 {
+		t.Fatal("reader:", code)
+	}
+	if w := serveWith(e, "GET", "/files/docs", "X-User", "reader"); w.Code != 301 || w.Header().Get("Location") != "/files/docs/" {
+		t.Fatal("redirect:", w.Code, w.Header())
+	}
+	if w := serveWith(e, "GET", "/files/docs/", "X-User", "reader"); w.Code != 200 || !strings.Contains(w.Body.String(), "a.txt") {
+		t.Fatal("browse:", w.Code, w.Body.String())
+	}
+	if w := serveWith(e, "GET", "/files", "X-User", "reader"); w.Code != 301 || w.Header().Get("Location") != "/files/" {
+		t.Fatal("prefix redirect:", w.Code, w.Header())
+	}
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// Timeout returns a middleware which limits the time of handling a request to
+// d, eg. for routes of a group:
+//
+//	reports := y.Group("/reports").With(yap.Timeout(30 * time.Second))
+//
+// The request context is cancelled when d is exceeded. If the handler hasn't
+// written the response header then, the client is replied code (default is
+// 503 Service Unavailable; 504 Gateway Timeout suits handlers waiting for
+// upstreams). After that, writes of the handler fail with
+// http.ErrHandlerTimeout, so it should return soon, eg. by checking
+// ctx.Err() or passing ctx to downstream calls.
+func Timeout(d time.Duration, code ...int) func(h http.Handler) http.Handler {
+	status := http.StatusServiceUnavailable
+	if code != nil {
+		status = code[0]
+	}
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			tw := &timeoutWriter{w: w, h: make(http.Header), ctx: ctx, code: status}
+			done := make(chan struct{})
+			stop := context.AfterFunc(ctx, func() {
+				defer close(done)
+				tw.mutex.Lock()
+				defer tw.mutex.Unlock()
+				tw.expired()
+			})
+			h.ServeHTTP(tw, r.WithContext(ctx))
+			if !stop() {
+				<-done // don't return while the timeout reply is being written
+			}
+		})
+	}
+}
+
+// timeoutWriter serializes writes of a handler and the timeout reply. The
+// handler writes its own header map, which is copied when the header is
+// written, so the timeout reply doesn't race with it.
+type timeoutWriter struct {
+	w     http.ResponseWriter
+	h     http.Header
+	ctx   context.Context
+	code  int // of the timeout reply
+	mutex sync.Mutex
+
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.h
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	if !tw.expired() && !tw.wroteHeader {
+		tw.writeHeader(code)
+	}
+}
+
+func (tw *timeoutWriter) writeHeader(code int) {
+	dst := tw.w.Header()
+	for k, v := range tw.h {
+		dst[k] = v
+	}
+	if code >= 200 {
+		tw.wroteHeader = true
+	}
+	tw.w.WriteHeader(code)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	if tw.expired() {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.writeHeader(http.StatusOK)
+	}
+	return tw.w.Write(b)
+}
+
+// Flush implements the http.Flusher interface.
+func (tw *timeoutWriter) Flush() {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	if f, ok := tw.w.(http.Flusher); ok && !tw.expired() {
+		if !tw.wroteHeader {
+			tw.writeHeader(http.StatusOK)
+		}
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.w
+}
+
+// expired reports whether the deadline is exceeded. The first call after the
+// deadline (by the handler or the timer, whichever is earlier) replies the
+// timeout code if the response header isn't written yet. The reply has
+// Content-Length and is flushed, so the client gets it completely even if the
+// handler is still running. It must be called with tw.mutex locked.
+func (tw *timeoutWriter) expired() bool {
+	if tw.timedOut || tw.ctx.Err() != context.DeadlineExceeded {
+		return tw.timedOut
+	}
+	tw.timedOut = true
+	if !tw.wroteHeader {
+		body := http.StatusText(tw.code) + "\n"
+		h := tw.w.Header()
+		h.Set("Content-Type", "text/plain; charset=utf-8")
+		h.Set("Content-Length", strconv.Itoa(len(body)))
+		h.Set("X-Content-Type-Options", "nosniff")
+		tw.w.WriteHeader(tw.code)
+		tw.w.Write([]byte(body))
+		if f, ok := tw.w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+	return true
+}
+
+// -----------------------------------------------------------------------------
+
+// Deadline, Done, Err and Value make Context a context.Context of the request,
+// so it can be passed to downstream calls (eg. sql queries or http requests)
+// which should be cancelled when the client is gone or Timeout is exceeded.
+
+func (p *Context) Deadline() (deadline time.Time, ok bool) {
+	return p.Request.Context().Deadline()
+}
+
+func (p *Context) Done() <-chan struct{} {
+	return p.Request.Context().Done()
+}
+
+func (p *Context) Err() error {
+	return p.Request.Context().Err()
+}
+
+func (p *Context) Value(key any) any {
+	return p.Request.Context().Value(key)
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goplus/yap"
+)
+
+func TestTimeout(t *testing.T) {
+	e := yap.New()
+	writeErr := make(chan error, 1)
+	slow := e.Group("/slow").With(yap.Timeout(20 * time.Millisecond))
+	slow.GET("/wait", func(ctx *yap.Context) {
+		ctx.ResponseWriter.Header().Set("X-Partial", "1")
+		<-ctx.Done() // *yap.Context is a context.Context
+		_, err := ctx.ResponseWriter.Write([]byte("late"))
+		writeErr <- err
+	})
+	slow.GET("/fast", func(ctx *yap.Context) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("Deadline: not set")
+		}
+		ctx.ResponseWriter.Header().Set("X-Fast", "1")
+		ctx.Text__2("ok")
+	})
+	e.GET("/gateway", func(ctx *yap.Context) {
+		<-ctx.Done()
+	})
+	e.Use(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/gateway" {
+				h = yap.Timeout(time.Millisecond, http.StatusGatewayTimeout)(h)
+			}
+			h.ServeHTTP(w, r)
+		})
+	})
+
+	w := serveWith(e, "GET", "/slow/wait")
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != "Service Unavailable\n" || w.Header().Get("X-Partial") != "" {
+		t.Fatal("Timeout:", w.Code, w.Body.String(), w.Header())
+	}
+	if err := <-writeErr; err != http.ErrHandlerTimeout {
+		t.Fatal("Write after timeout:", err)
+	}
+	if w = serveWith(e, "GET", "/slow/fast"); w.Code != 200 || w.Body.String() != "ok" || w.Header().Get("X-Fast") != "1" {
+		t.Fatal("Timeout: fast", w.Code, w.Body.String(), w.Header())
+	}
+	if w = serveWith(e, "GET", "/gateway"); w.Code != http.StatusGatewayTimeout {
+		t.Fatal("Timeout: gateway", w.Code)
+	}
+}
+
+func TestTimeoutFlush(t *testing.T) {
+	e := yap.New()
+	release := make(chan struct{})
+	e.GET("/sleep", func(ctx *yap.Context) {
+		select { // ignores ctx
+		case <-release:
+		case <-time.After(3 * time.Second):
+		}
+	})
+	ts := httptest.NewServer(e.Handler(yap.Timeout(20 * time.Millisecond)))
+	defer ts.Close()
+	defer close(release)
+
+	start := time.Now()
+	resp, err := http.Get(ts.URL + "/sleep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || string(body) != "Service Unavailable\n" {
+		t.Fatal("Timeout:", resp.StatusCode, string(body), err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatal("Timeout: replied after", d)
+	}
+}
+
+// ctxStore is a session store recording the contexts of its operations.
+type ctxStore struct {
+	yap.SessionStore
+	ctxs []context.Context
+}
+
+func (p *ctxStore) LoadContext(ctx context.Context, cookie string) ([]byte, error) {
+	p.ctxs = append(p.ctxs, ctx)
+	return p.Load(cookie)
+}
+
+func (p *ctxStore) SaveContext(ctx context.Context, cookie string, data []byte, maxAge time.Duration) (string, error) {
+	p.ctxs = append(p.ctxs, ctx)
+	return p.Save(cookie, data, maxAge)
+}
+
+func (p *ctxStore) DeleteContext(ctx context.Context, cookie string) error {
+	p.ctxs = append(p.ctxs, ctx)
+	return p.Delete(cookie)
+}
+
+func TestContextSessionStore(t *testing.T) {
+	store := &ctxStore{SessionStore: yap.NewMemoryStore()}
+	e := sessionEngine(store)
+	e.Use(yap.Timeout(time.Minute))
+	w := serve(e, "GET", "/login?user=bob")
+	if w = serve(e, "GET", "/me", w.Result().Cookies()...); w.Body.String() != "bob" {
+		t.Fatal("session:", w.Body.String())
+	}
+	if len(store.ctxs) != 2 {
+		t.Fatal("ContextSessionStore:", len(store.ctxs))
+	}
+	for _, ctx := range store.ctxs {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Fatal("ContextSessionStore: not the request context")
+		}
+	}
+}
@@ -0,0 +1,386 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package trace is a small tracing abstraction used by yap and ydb. It
+// propagates W3C Trace Context (the traceparent header), and can be adapted to
+// tracing systems like OpenTelemetry by implementing the Tracer interface.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// TraceID is the ID of a trace.
+type TraceID [16]byte
+
+// String returns the hex form of the ID.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid reports whether the ID isn't all zeros.
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// SpanID is the ID of a span.
+type SpanID [8]byte
+
+// String returns the hex form of the ID.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid reports whether the ID isn't all zeros.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// SpanContext identifies a span, and is propagated across process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+	Remote  bool // propagated from a remote parent
+}
+
+// IsValid reports whether both the trace ID and the span ID are valid.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent returns the W3C traceparent header value of sc.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent parses a W3C traceparent header value.
+func ParseTraceparent(s string) (sc SpanContext, ok bool) {
+	// version(2) - trace-id(32) - parent-id(16) - flags(2)
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' || (len(s) > 55 && s[55] != '-') {
+		return
+	}
+	var ver, flags [1]byte
+	if _, err := hex.Decode(ver[:], []byte(s[:2])); err != nil || ver[0] == 0xff || (ver[0] == 0 && len(s) != 55) {
+		return
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(s[3:35])); err != nil {
+		return
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(s[36:52])); err != nil {
+		return
+	}
+	if _, err := hex.Decode(flags[:], []byte(s[53:55])); err != nil {
+		return
+	}
+	sc.Sampled = flags[0]&1 != 0
+	sc.Remote = true
+	return sc, sc.IsValid()
+}
+
+const traceparentHeader = "traceparent"
+
+// Extract returns a context carrying the remote span context of the
+// traceparent header (if it is valid), as the parent of spans started from it.
+func Extract(ctx context.Context, h http.Header) context.Context {
+	if sc, ok := ParseTraceparent(h.Get(traceparentHeader)); ok {
+		return context.WithValue(ctx, remoteKey{}, sc)
+	}
+	return ctx
+}
+
+// Inject sets the traceparent header by the span of ctx.
+func Inject(ctx context.Context, h http.Header) {
+	if sc := SpanFromContext(ctx).SpanContext(); sc.IsValid() {
+		h.Set(traceparentHeader, sc.Traceparent())
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// Kind is the kind of a span.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindServer
+	KindClient
+)
+
+// Span represents an operation in a trace.
+type Span interface {
+	// SpanContext returns the span context of the span.
+	SpanContext() SpanContext
+
+	// SetName changes the name of the span.
+	SetName(name string)
+
+	// SetAttr sets an attribute of the span.
+	SetAttr(key string, value any)
+
+	// RecordError marks the span as failed.
+	RecordError(err error)
+
+	// End completes the span.
+	End()
+}
+
+// Tracer starts spans.
+type Tracer interface {
+	// Start starts a span as a child of the span of ctx (see Parent), and
+	// returns a context carrying the new span (see ContextWithSpan).
+	Start(ctx context.Context, name string, kind Kind) (context.Context, Span)
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// ContextWithSpan returns a context carrying span.
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span of ctx. It returns a no-op span if ctx
+// doesn't carry a span.
+func SpanFromContext(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span
+	}
+	return noopSpan{}
+}
+
+// Parent returns the span context of the span of ctx, or the remote span
+// context extracted by Extract.
+func Parent(ctx context.Context) SpanContext {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span.SpanContext()
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SpanContext() SpanContext { return SpanContext{} }
+func (noopSpan) SetName(string)           {}
+func (noopSpan) SetAttr(string, any)      {}
+func (noopSpan) RecordError(error)        {}
+func (noopSpan) End()                     {}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, name string, kind Kind) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type tracerHolder struct {
+	Tracer
+}
+
+var global atomic.Pointer[tracerHolder]
+
+// SetTracer sets the global tracer used by yap and ydb. If t is nil, tracing
+// is disabled.
+func SetTracer(t Tracer) {
+	if t == nil {
+		global.Store(nil)
+		return
+	}
+	global.Store(&tracerHolder{t})
+}
+
+// Enabled reports whether a global tracer is set.
+func Enabled() bool {
+	return global.Load() != nil
+}
+
+// Start starts a span by the global tracer. It returns a no-op span if no
+// tracer is set.
+func Start(ctx context.Context, name string, kind Kind) (context.Context, Span) {
+	if h := global.Load(); h != nil {
+		return h.Start(ctx, name, kind)
+	}
+	return noopTracer{}.Start(ctx, name, kind)
+}
+
+// -----------------------------------------------------------------------------
+
+// SpanData represents a completed span.
+type SpanData struct {
+	Name        string
+	Kind        Kind
+	SpanContext SpanContext
+	Parent      SpanContext
+	Start       time.Time
+	End         time.Time
+	Attrs       map[string]any
+	Err         error
+}
+
+// Exporter exports completed spans.
+type Exporter interface {
+	Export(span *SpanData)
+}
+
+// NewTracer creates a Tracer which exports sampled spans to exp. Spans are
+// sampled if their parent is, and root spans are always sampled.
+func NewTracer(exp Exporter) Tracer {
+	return &tracer{exp: exp}
+}
+
+type tracer struct {
+	exp Exporter
+}
+
+func (p *tracer) Start(ctx context.Context, name string, kind Kind) (context.Context, Span) {
+	parent := Parent(ctx)
+	sc := SpanContext{TraceID: parent.TraceID, Sampled: true}
+	if parent.IsValid() {
+		sc.Sampled = parent.Sampled
+	} else {
+		rand.Read(sc.TraceID[:])
+	}
+	rand.Read(sc.SpanID[:])
+	span := &span{tracer: p, data: SpanData{
+		Name: name, Kind: kind, SpanContext: sc, Parent: parent, Start: time.Now(),
+	}}
+	return ContextWithSpan(ctx, span), span
+}
+
+type span struct {
+	tracer *tracer
+	data   SpanData
+	ended  bool
+	mutex  sync.Mutex
+}
+
+func (p *span) SpanContext() SpanContext {
+	return p.data.SpanContext
+}
+
+func (p *span) SetName(name string) {
+	p.mutex.Lock()
+	p.data.Name = name
+	p.mutex.Unlock()
+}
+
+func (p *span) SetAttr(key string, value any) {
+	p.mutex.Lock()
+	if p.data.Attrs == nil {
+		p.data.Attrs = make(map[string]any)
+	}
+	p.data.Attrs[key] = value
+	p.mutex.Unlock()
+}
+
+func (p *span) RecordError(err error) {
+	p.mutex.Lock()
+	p.data.Err = err
+	p.mutex.Unlock()
+}
+
+func (p *span) End() {
+	p.mutex.Lock()
+	if p.ended {
+		p.mutex.Unlock()
+		return
+	}
+	p.ended = true
+	p.data.End = time.Now()
+	data := p.data
+	p.mutex.Unlock()
+	if data.SpanContext.Sampled {
+		p.tracer.exp.Export(&data)
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// InMemoryExporter keeps completed spans in memory. It is used in tests.
+type InMemoryExporter struct {
+	spans []*SpanData
+	mutex sync.Mutex
+}
+
+// NewInMemoryExporter creates an InMemoryExporter.
+func NewInMemoryExporter() *InMemoryExporter {
+	return new(InMemoryExporter)
+}
+
+// Export implements Exporter.Export.
+func (p *InMemoryExporter) Export(span *SpanData) {
+	p.mutex.Lock()
+	p.spans = append(p.spans, span)
+	p.mutex.Unlock()
+}
+
+// Spans returns completed spans in the order they ended.
+func (p *InMemoryExporter) Spans() []*SpanData {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]*SpanData(nil), p.spans...)
+}
+
+// Reset removes all spans.
+func (p *InMemoryExporter) Reset() {
+	p.mutex.Lock()
+	p.spans = nil
+	p.mutex.Unlock()
+}
+
+// -----------------------------------------------------------------------------
+
+// Transport is an http.RoundTripper which starts a client span for each
+// request and injects the traceparent header.
+type Transport struct {
+	// Base is the underlying RoundTripper. It is http.DefaultTransport if nil.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (p *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := p.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	ctx, span := Start(req.Context(), "HTTP "+req.Method, KindClient)
+	defer span.End()
+	span.SetAttr("http.method", req.Method)
+	span.SetAttr("url.full", req.URL.String())
+	req = req.Clone(ctx)
+	Inject(ctx, req.Header)
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttr("http.status_code", resp.StatusCode)
+	return resp, nil
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestTraceparent(t *testing.T) {
+	const tp = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, ok := ParseTraceparent(tp)
+	if !ok || !sc.Sampled || !sc.Remote || sc.SpanID.String() != "00f067aa0ba902b7" {
+		t.Fatal("ParseTraceparent:", sc, ok)
+	}
+	if got := sc.Traceparent(); got != tp {
+		t.Fatal("Traceparent:", got)
+	}
+	for _, bad := range []string{
+		"",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+	} {
+		if _, ok := ParseTraceparent(bad); ok {
+			t.Fatal("ParseTraceparent accepted:", bad)
+		}
+	}
+	if _, ok := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra"); !ok {
+		t.Fatal("ParseTraceparent: future version")
+	}
+}
+
+func TestTracer(t *testing.T) {
+	exp := NewInMemoryExporter()
+	SetTracer(NewTracer(exp))
+	defer SetTracer(nil)
+
+	h := http.Header{}
+	h.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, root := Start(Extract(context.Background(), h), "root", KindServer)
+	_, child := Start(ctx, "child", KindInternal)
+	child.SetAttr("k", 1)
+	child.RecordError(errors.New("failed"))
+	child.End()
+	child.End()
+	root.End()
+
+	spans := exp.Spans()
+	if len(spans) != 2 || spans[0].Name != "child" || spans[1].Name != "root" {
+		t.Fatal("Spans:", spans)
+	}
+	c, r := spans[0], spans[1]
+	if r.SpanContext.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || r.Parent.SpanID.String() != "00f067aa0ba902b7" {
+		t.Fatal("root:", r)
+	}
+	if c.Parent != r.SpanContext || c.Attrs["k"] != 1 || c.Err == nil {
+		t.Fatal("child:", c)
+	}
+
+	out := http.Header{}
+	Inject(ctx, out)
+	if sc, ok := ParseTraceparent(out.Get("traceparent")); !ok || sc.SpanID != r.SpanContext.SpanID {
+		t.Fatal("Inject:", out)
+	}
+
+	// not sampled
+	exp.Reset()
+	h.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	_, span := Start(Extract(context.Background(), h), "root", KindServer)
+	span.End()
+	if len(exp.Spans()) != 0 {
+		t.Fatal("unsampled span exported")
+	}
+
+	SetTracer(nil)
+	if Enabled() {
+		t.Fatal("Enabled")
+	}
+	if _, span := Start(context.Background(), "noop", KindInternal); span.SpanContext().IsValid() {
+		t.Fatal("noop span")
+	}
+}
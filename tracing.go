@@ -0,0 +1,57 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/goplus/yap/trace"
+)
+
+// Tracing returns a middleware which starts a server span for each request by
+// the global tracer (see trace.SetTracer). The parent span is extracted from
+// the W3C traceparent header. The span is named after the route pattern (eg.
+// "GET /p/:id"), and is available by trace.SpanFromContext(ctx.Context()).
+func Tracing() func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, span := trace.Start(trace.Extract(r.Context(), r.Header), "HTTP "+r.Method, trace.KindServer)
+			defer span.End()
+			rw := wrapResponse(w)
+			r, route := routeRecorder(r.WithContext(ctx))
+			h.ServeHTTP(rw, r)
+			status := rw.Status()
+			if *route != "" {
+				span.SetName(r.Method + " " + *route)
+				span.SetAttr("http.route", *route)
+			}
+			span.SetAttr("http.method", r.Method)
+			span.SetAttr("url.path", r.URL.Path)
+			span.SetAttr("http.status_code", status)
+			if status >= 500 {
+				span.RecordError(httpError(status))
+			}
+		})
+	}
+}
+
+type httpError int
+
+func (e httpError) Error() string {
+	return "HTTP " + strconv.Itoa(int(e)) + " " + http.StatusText(int(e))
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/goplus/yap"
+	"github.com/goplus/yap/trace"
+)
+
+func TestTracing(t *testing.T) {
+	exp := trace.NewInMemoryExporter()
+	trace.SetTracer(trace.NewTracer(exp))
+	defer trace.SetTracer(nil)
+
+	e := yap.New(fstest.MapFS{
+		"page_yap.html": {Data: []byte(`<p>{{.}}</p>`)},
+	})
+	e.Use(yap.Tracing())
+	var handlerSpan trace.SpanContext
+	e.GET("/p/:id", func(ctx *yap.Context) {
+		handlerSpan = trace.SpanFromContext(ctx.Context()).SpanContext()
+		ctx.YAP(200, "page", ctx.Param("id"))
+	})
+
+	req := httptest.NewRequest("GET", "/p/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exp.Spans()
+	if len(spans) != 2 {
+		t.Fatal("spans:", spans)
+	}
+	render, server := spans[0], spans[1]
+	if server.Name != "GET /p/:id" || server.Kind != trace.KindServer ||
+		server.Attrs["http.status_code"] != 200 || server.Attrs["http.route"] != "/p/:id" {
+		t.Fatal("server span:", server)
+	}
+	if server.Parent.SpanID.String() != "00f067aa0ba902b7" ||
+		server.SpanContext.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatal("server span parent:", server.Parent)
+	}
+	if handlerSpan != server.SpanContext {
+		t.Fatal("ctx.Context() span:", handlerSpan)
+	}
+	if render.Name != "yap page" || render.Parent != server.SpanContext {
+		t.Fatal("render span:", render)
+	}
+
+	exp.Reset()
+	serve(e, "GET", "/nothing")
+	if spans := exp.Spans(); len(spans) != 1 || spans[0].Name != "HTTP GET" || spans[0].Parent.IsValid() {
+		t.Fatal("unmatched:", spans)
+	}
+}
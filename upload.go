@@ -0,0 +1,391 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+
+// BodyLimit returns a middleware which limits the request body to n bytes.
+// Requests declaring a larger Content-Length get a 413 error at once. For
+// others (eg. chunked bodies), reading beyond the limit fails with an error
+// wrapping *http.MaxBytesError (see MultipartForm and FormFile), and
+// ctx.Param and upload reply a 413 error then.
+//
+// It can be applied to the engine (y.Use), a route group (Group.Use) or a
+// single route (y.With), eg.
+//
+//	y.With(yap.BodyLimit(10 << 20)).POST("/upload", upload)
+func BodyLimit(n int64) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > n {
+				w.Header().Set("Connection", "close")
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = http.MaxBytesReader(w, r.Body, n)
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+const defaultMaxMemory = 32 << 20 // 32 MB
+
+// UploadOptions represents options of multipart form parsing.
+type UploadOptions struct {
+	// MaxMemory is the max bytes of uploaded files kept in memory. Files
+	// beyond it are spilled to temp files. It is 32 MB by default.
+	MaxMemory int64
+
+	// TempDir is the directory of temp files. It is os.TempDir() by default.
+	TempDir string
+}
+
+// SetUploadOptions sets options of multipart form parsing, which is used by
+// ctx.MultipartForm, ctx.FormFile and ctx.Param.
+func (p *Engine) SetUploadOptions(opts UploadOptions) {
+	p.uploads = opts
+}
+
+// MultipartForm represents a parsed multipart form.
+type MultipartForm struct {
+	Value map[string][]string
+	File  map[string][]*UploadedFile
+}
+
+// RemoveAll removes temp files of the form. It is called automatically after
+// the request is handled.
+func (p *MultipartForm) RemoveAll() (err error) {
+	for _, files := range p.File {
+		for _, f := range files {
+			if f.tmpfile != "" {
+				if e := os.Remove(f.tmpfile); e != nil && !errors.Is(e, os.ErrNotExist) && err == nil {
+					err = e
+				}
+			}
+		}
+	}
+	return
+}
+
+// UploadedFile represents a file part of a multipart form.
+type UploadedFile struct {
+	Filename    string // base name of the file given by the client
+	Header      textproto.MIMEHeader
+	Size        int64
+	ContentType string // content type sniffed by the content, see http.DetectContentType
+
+	content []byte
+	tmpfile string
+}
+
+// Open opens the file.
+func (p *UploadedFile) Open() (multipart.File, error) {
+	if p.tmpfile != "" {
+		return os.Open(p.tmpfile)
+	}
+	r := io.NewSectionReader(bytes.NewReader(p.content), 0, int64(len(p.content)))
+	return sectionReadCloser{r}, nil
+}
+
+type sectionReadCloser struct {
+	*io.SectionReader
+}
+
+func (sectionReadCloser) Close() error {
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+// uploadState is the multipart form of a request. It is shared by copies of
+// the Context (eg. classfile handlers) and the CSRF middleware, so the body is
+// parsed once and temp files are removed by the owner (see withRoute).
+type uploadState struct {
+	form     *MultipartForm // nil if not parsed
+	formErr  error
+	bodyErr  error // error of Request.ParseForm
+	tooLarge bool  // the request is replied with a 413 error
+}
+
+type uploadKey struct{}
+
+// requestUploads returns the upload state put in the request context by a
+// middleware (see CSRF), or nil if there is none.
+func requestUploads(r *http.Request) *uploadState {
+	st, _ := r.Context().Value(uploadKey{}).(*uploadState)
+	return st
+}
+
+func (p *Context) uploadState() *uploadState {
+	if p.uploads == nil {
+		if p.uploads = requestUploads(p.Request); p.uploads == nil {
+			p.uploads = new(uploadState)
+		}
+	}
+	return p.uploads
+}
+
+func (p *uploadState) removeAll() {
+	if p.form != nil {
+		p.form.RemoveAll()
+	}
+}
+
+// MultipartForm parses the request body as a multipart form by streaming.
+// Uploaded files are kept in memory up to UploadOptions.MaxMemory bytes, and
+// spilled to temp files beyond it. The form is parsed once, and its values
+// are also available by ctx.Param.
+func (p *Context) MultipartForm() (*MultipartForm, error) {
+	st := p.uploadState()
+	if st.form == nil {
+		st.form, st.formErr = parseMultipart(p.Request, p.engine.uploads)
+		p.setFormParams()
+	}
+	return st.form, st.formErr
+}
+
+// parseForm parses the url query and the url-encoded request body, and
+// returns the error of parsing them if any.
+func (p *Context) parseForm() error {
+	st := p.uploadState()
+	if err := p.Request.ParseForm(); err != nil { // only the first call fails
+		st.bodyErr = err
+	}
+	p.setFormParams()
+	return st.bodyErr
+}
+
+// replyTooLarge replies a 413 error if err is caused by a body beyond
+// BodyLimit, and drops the response written by the handler after it.
+func (p *Context) replyTooLarge(err error) bool {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return false
+	}
+	if st := p.uploadState(); !st.tooLarge {
+		st.tooLarge = true
+		p.ResponseWriter.Header().Set("Connection", "close")
+		http.Error(p.ResponseWriter, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+	}
+	if _, ok := p.ResponseWriter.(discardWriter); !ok {
+		p.ResponseWriter = discardWriter{make(http.Header)}
+	}
+	return true
+}
+
+// discardWriter drops the response of a handler, after the request is replied
+// with an error.
+type discardWriter struct {
+	header http.Header
+}
+
+func (p discardWriter) Header() http.Header {
+	return p.header
+}
+
+func (discardWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (discardWriter) WriteHeader(code int) {}
+
+// FormFile returns the first file uploaded in the form field name. It returns
+// http.ErrMissingFile if there is no such file.
+func (p *Context) FormFile(name string) (*UploadedFile, error) {
+	form, err := p.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+	if files := form.File[name]; len(files) > 0 {
+		return files[0], nil
+	}
+	return nil, http.ErrMissingFile
+}
+
+// SaveUploadedFile saves file to dst, creating its directory if needed.
+func (p *Context) SaveUploadedFile(file *UploadedFile, dst string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if err = os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, src)
+	if e := out.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// upload saves the file uploaded in the form field name into dir, with a
+// random name keeping the file extension, and returns the path saved. If it
+// fails, the request is replied with a 413 error (if the body is too large)
+// or a 400 error, and the error is returned.
+func (p *Context) upload(name, dir string) (path string, err error) {
+	file, err := p.FormFile(name)
+	if err == nil {
+		path = filepath.Join(dir, newUploadName(file.Filename))
+		if err = p.SaveUploadedFile(file, path); err != nil {
+			http.Error(p.ResponseWriter, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return "", err
+		}
+		return
+	}
+	if !p.replyTooLarge(err) {
+		http.Error(p.ResponseWriter, err.Error(), http.StatusBadRequest)
+	}
+	return "", err
+}
+
+func newUploadName(filename string) string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:]) + strings.ToLower(filepath.Ext(filename))
+}
+
+func isMultipart(r *http.Request) bool {
+	mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && (mt == "multipart/form-data" || mt == "multipart/mixed")
+}
+
+func parseMultipart(r *http.Request, o UploadOptions) (*MultipartForm, error) {
+	form := &MultipartForm{
+		Value: make(map[string][]string),
+		File:  make(map[string][]*UploadedFile),
+	}
+	if !isMultipart(r) {
+		return form, http.ErrNotMultipart
+	}
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return form, err
+	}
+	maxMemory := o.MaxMemory
+	if maxMemory <= 0 {
+		maxMemory = defaultMaxMemory
+	}
+	maxValueBytes := int64(10 << 20) // 10 MB for non-file parts, as net/http does
+	defer mergeForm(r, form)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return form, nil
+		}
+		if err != nil {
+			return form, err
+		}
+		name := part.FormName()
+		if name == "" {
+			part.Close()
+			continue
+		}
+		if part.FileName() == "" {
+			var b strings.Builder
+			n, err := io.CopyN(&b, part, maxValueBytes+1)
+			if err != nil && err != io.EOF {
+				return form, err
+			}
+			if maxValueBytes -= n; maxValueBytes < 0 {
+				return form, multipart.ErrMessageTooLarge
+			}
+			form.Value[name] = append(form.Value[name], b.String())
+			continue
+		}
+		file, err := readUploadedFile(part, &maxMemory, o.TempDir)
+		if file != nil {
+			form.File[name] = append(form.File[name], file)
+		}
+		if err != nil {
+			return form, err
+		}
+	}
+}
+
+func readUploadedFile(part *multipart.Part, maxMemory *int64, tempDir string) (*UploadedFile, error) {
+	file := &UploadedFile{Filename: part.FileName(), Header: part.Header}
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, part, *maxMemory+1)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if n > *maxMemory { // spill to a temp file
+		tmp, err := os.CreateTemp(tempDir, "yap-upload-*")
+		if err != nil {
+			return nil, err
+		}
+		file.tmpfile = tmp.Name()
+		size, err := io.Copy(tmp, io.MultiReader(&buf, part))
+		if e := tmp.Close(); err == nil {
+			err = e
+		}
+		file.Size = size
+		file.ContentType = sniffFile(file)
+		return file, err
+	}
+	*maxMemory -= n
+	file.content = buf.Bytes()
+	file.Size = n
+	file.ContentType = sniffFile(file)
+	return file, nil
+}
+
+func sniffFile(file *UploadedFile) string {
+	f, err := file.Open()
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer f.Close()
+	var b [512]byte
+	n, _ := io.ReadFull(f, b[:])
+	return http.DetectContentType(b[:n])
+}
+
+// mergeForm makes values of the multipart form available by Request.FormValue.
+func mergeForm(r *http.Request, form *MultipartForm) {
+	r.ParseForm()
+	for k, v := range form.Value {
+		r.Form[k] = append(r.Form[k], v...)
+		r.PostForm[k] = append(r.PostForm[k], v...)
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,192 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yap_test
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goplus/yap"
+)
+
+var pngData = append([]byte("\x89PNG\x0D\x0A\x1A\x0A"), bytes.Repeat([]byte{0}, 100)...)
+
+func multipartBody(t *testing.T, filename string, data []byte) (io.Reader, string) {
+	t.Helper()
+	var b bytes.Buffer
+	mw := multipart.NewWriter(&b)
+	mw.WriteField("title", "hi")
+	fw, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write(data)
+	mw.Close()
+	return &b, mw.FormDataContentType()
+}
+
+func postUpload(t *testing.T, e http.Handler, path string, body io.Reader, ct string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", path, body)
+	req.Header.Set("Content-Type", ct)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestUpload(t *testing.T) {
+	tmp, dir := t.TempDir(), t.TempDir()
+	e := yap.New()
+	e.SetUploadOptions(yap.UploadOptions{MaxMemory: 16, TempDir: tmp})
+	e.POST("/upload/:kind", func(ctx *yap.Context) {
+		if ctx.Param("kind") != "img" || ctx.Param("title") != "hi" {
+			t.Fatal("Param:", ctx.Param("kind"), ctx.Param("title"))
+		}
+		f, err := ctx.FormFile("file")
+		if err != nil {
+			t.Fatal("FormFile:", err)
+		}
+		if f.Filename != "a.PNG" || f.Size != int64(len(pngData)) || f.ContentType != "image/png" {
+			t.Fatal("FormFile:", f.Filename, f.Size, f.ContentType)
+		}
+		if entries, _ := os.ReadDir(tmp); len(entries) != 1 {
+			t.Fatal("spilled files:", len(entries))
+		}
+		if _, err = ctx.FormFile("none"); err != http.ErrMissingFile {
+			t.Fatal("FormFile none:", err)
+		}
+		path, err := ctx.Upload__1(dir)
+		if err != nil {
+			t.Fatal("Upload:", err)
+		}
+		ctx.Text__2(filepath.Base(path))
+	})
+
+	body, ct := multipartBody(t, "../a.PNG", pngData)
+	w := postUpload(t, e, "/upload/img", body, ct)
+	if w.Code != 200 || !strings.HasSuffix(w.Body.String(), ".png") {
+		t.Fatal("POST /upload:", w.Code, w.Body.String())
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, w.Body.String())); err != nil || !bytes.Equal(data, pngData) {
+		t.Fatal("saved file:", err)
+	}
+	if entries, _ := os.ReadDir(tmp); len(entries) != 0 {
+		t.Fatal("temp files not removed:", len(entries))
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	dir := t.TempDir()
+	e := yap.New()
+	e.With(yap.BodyLimit(1024)).POST("/upload", func(ctx *yap.Context) {
+		if _, err := ctx.Upload__1(dir); err == nil {
+			ctx.Text__2("ok")
+		}
+	})
+
+	large := bytes.Repeat(pngData, 20)
+	body, ct := multipartBody(t, "a.png", large)
+	if w := postUpload(t, e, "/upload", body, ct); w.Code != 413 {
+		t.Fatal("Content-Length:", w.Code)
+	}
+	body, ct = multipartBody(t, "a.png", large)
+	body = io.MultiReader(body) // unknown length
+	if w := postUpload(t, e, "/upload", body, ct); w.Code != 413 {
+		t.Fatal("streaming:", w.Code)
+	}
+	body, ct = multipartBody(t, "a.png", pngData)
+	if w := postUpload(t, e, "/upload", body, ct); w.Code != 200 {
+		t.Fatal("small:", w.Code)
+	}
+	if w := postUpload(t, e, "/upload", strings.NewReader("a=1"), "application/x-www-form-urlencoded"); w.Code != 400 {
+		t.Fatal("not multipart:", w.Code)
+	}
+}
+
+type UploadAppV2 struct {
+	yap.AppV2
+	dir string
+}
+
+func (p *UploadAppV2) Main() {
+	yap.XGot_AppV2_Main(p, &uploadHandler{})
+}
+
+type uploadHandler struct {
+	yap.Handler
+	*UploadAppV2
+}
+
+func (p *uploadHandler) Main(ctx *yap.Context) {
+	p.Handler.Main(ctx)
+	if path, err := p.Upload__1(p.dir); err == nil {
+		p.Text__2(filepath.Base(path))
+	}
+}
+
+func (p *uploadHandler) Classfname() string {
+	return "post_upload"
+}
+
+func (p *uploadHandler) Classclone() yap.HandlerProto {
+	ret := *p
+	return &ret
+}
+
+func TestUploadClassfile(t *testing.T) {
+	tmp := t.TempDir()
+	app := &UploadAppV2{dir: t.TempDir()}
+	app.InitYap()
+	app.SetLAS(func(addr string, h http.Handler) error { return nil })
+	app.SetUploadOptions(yap.UploadOptions{MaxMemory: 16, TempDir: tmp})
+	app.Main()
+
+	body, ct := multipartBody(t, "a.png", pngData)
+	if w := postUpload(t, app, "/upload", body, ct); w.Code != 200 || !strings.HasSuffix(w.Body.String(), ".png") {
+		t.Fatal("POST /upload:", w.Code, w.Body.String())
+	}
+	if entries, _ := os.ReadDir(tmp); len(entries) != 0 {
+		t.Fatal("temp files not removed:", len(entries))
+	}
+}
+
+func TestBodyLimitParam(t *testing.T) {
+	e := yap.New()
+	e.Use(yap.BodyLimit(16))
+	e.POST("/articles/:id", func(ctx *yap.Context) {
+		ctx.Text__2("created " + ctx.Param("id") + ctx.Param("title"))
+	})
+	body := io.MultiReader(strings.NewReader("title=" + strings.Repeat("a", 100))) // unknown length
+	w := postUpload(t, e, "/articles/1", body, "application/x-www-form-urlencoded")
+	if w.Code != 413 || w.Body.String() != "Request Entity Too Large\n" {
+		t.Fatal("Param: too large", w.Code, w.Body.String())
+	}
+	mpBody, ct := multipartBody(t, "a.png", pngData)
+	if w = postUpload(t, e, "/articles/1", io.MultiReader(mpBody), ct); w.Code != 413 || w.Body.String() != "Request Entity Too Large\n" {
+		t.Fatal("Param: multipart too large", w.Code, w.Body.String())
+	}
+	if w = postUpload(t, e, "/articles/1", strings.NewReader("title=a"), "application/x-www-form-urlencoded"); w.Code != 200 || w.Body.String() != "created 1a" {
+		t.Fatal("Param:", w.Code, w.Body.String())
+	}
+}
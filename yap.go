@@ -20,9 +20,14 @@ import (
 	"html/template"
 	"io/fs"
 	"log"
+	"net"
 	"net/http"
+	"net/netip"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/goplus/yap/internal/htmltempl"
 	"github.com/goplus/yap/noredirect"
@@ -38,9 +43,34 @@ type Engine struct {
 
 	delimLeft, delimRight string
 
-	tpl htmltempl.Template
-	fs  fs.FS
-	las func(addr string, handler http.Handler) error
+	tpl      htmltempl.Template // parsed templates, executed by clones in tplPool
+	tplPool  sync.Pool
+	tplOnce  sync.Once
+	funcs    template.FuncMap
+	ctxFuncs map[string]func(ctx *Context) any
+	fs       fs.FS
+	las      func(addr string, handler http.Handler) error
+	mws      []func(h http.Handler) http.Handler
+	handler  http.Handler // p.mws applied
+
+	cookie *CookieCodec
+	sess   *sessionMgr
+
+	metrics *engineMetrics
+	uploads UploadOptions
+	etag    bool // see UseETag
+
+	services  services // see Provide
+	errRender func(ctx *Context, err error)
+	dev       bool // see SetDevMode
+	conf      any  // see LoadConfig
+	jobs      jobRunner
+	trusted   []netip.Prefix // see SetTrustedProxies
+
+	srv        atomic.Pointer[http.Server] // server started by Run
+	draining   atomic.Bool
+	drainDelay time.Duration // see HealthOptions
+	checks     []healthCheck
 }
 
 // New creates a YAP engine.
@@ -54,7 +84,7 @@ func New(fs ...fs.FS) *Engine {
 func (p *Engine) InitYap(fs ...fs.FS) {
 	if p.Mux == nil {
 		p.Mux = http.NewServeMux()
-		p.las = http.ListenAndServe
+		p.las = p.listenAndServe
 		p.router.init()
 	}
 	if fs != nil {
@@ -88,9 +118,28 @@ func (p *Engine) NewContext(w http.ResponseWriter, r *http.Request) *Context {
 
 // ServeHTTP makes the router implement the http.Handler interface.
 func (p *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	req = p.withTrusted(req)
+	if p.handler != nil {
+		p.handler.ServeHTTP(w, req)
+		return
+	}
 	p.router.serveHTTP(w, req, p)
 }
 
+// Use adds middlewares which apply to all requests served by the engine,
+// including static files and handlers registered to Mux. The first middleware
+// added is the outermost one.
+func (p *Engine) Use(mws ...func(h http.Handler) http.Handler) {
+	p.mws = append(p.mws, mws...)
+	h := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		p.router.serveHTTP(w, req, p)
+	}))
+	for i := len(p.mws) - 1; i >= 0; i-- {
+		h = p.mws[i](h)
+	}
+	p.handler = h
+}
+
 // FS returns a $YapFS sub filesystem by specified a dir.
 func (p *Engine) FS(dir string) (ret fs.FS) {
 	return SubFS(p.yapFS(), dir)
@@ -107,7 +156,8 @@ func (p *Engine) Static(pattern string, dir ...fs.FS) {
 	p.StaticHttp(pattern, http.FS(fsys))
 }
 
-// StaticHttp serves static files from fsys (http.FileSystem).
+// StaticHttp serves static files from fsys (http.FileSystem). See StaticWith
+// for more options.
 func (p *Engine) StaticHttp(pattern string, fsys http.FileSystem, allowRedirect ...bool) {
 	if !strings.HasSuffix(pattern, "/") {
 		pattern += "/"
@@ -122,14 +172,32 @@ func (p *Engine) StaticHttp(pattern string, fsys http.FileSystem, allowRedirect
 	} else {
 		server = noredirect.FileServer(fsys)
 	}
-	p.Mux.Handle(pattern, http.StripPrefix(pattern, server))
+	server = http.StripPrefix(pattern, server)
+	p.static(pattern, func(ctx *Context) {
+		server.ServeHTTP(ctx.ResponseWriter, ctx.Request)
+	}, nil)
+}
+
+// static registers handle serving static files under prefix to the router
+// (see router.static), and to Mux for those serving requests by it.
+func (p *Engine) static(prefix string, handle func(ctx *Context), policies []*Policy) {
+	handle = p.router.static(prefix, handle, policies)
+	p.Mux.HandleFunc(prefix, func(w http.ResponseWriter, r *http.Request) {
+		handle(p.NewContext(w, r))
+	})
 }
 
 // Handle registers the handler function for the given pattern.
 func (p *Engine) Handle(pattern string, handle func(ctx *Context)) {
+	p.handle(pattern, handle, nil)
+}
+
+func (p *Engine) handle(pattern string, handle func(ctx *Context), policies []*Policy) {
+	handle = withRoute(pattern, handle)
 	p.Mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
 		handle(p.NewContext(w, r))
 	})
+	p.routes = append(p.routes, RouteInfo{Path: pattern, Policies: policies})
 }
 
 // Handler returns the main entry that responds to HTTP requests.
@@ -138,7 +206,12 @@ func (p *Engine) Handler(mws ...func(h http.Handler) http.Handler) http.Handler
 	for _, mw := range mws {
 		h = mw(h)
 	}
-	return h
+	if mws == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, p.withTrusted(r)) // mws also default to the trusted proxies
+	})
 }
 
 // Run listens on the TCP network address addr and then calls
@@ -148,12 +221,32 @@ func (p *Engine) Run(addr string, mws ...func(h http.Handler) http.Handler) erro
 	h := p.Handler(mws...)
 	log.Println("Listen", addr)
 	err := p.las(addr, h)
+	if err == http.ErrServerClosed { // see Shutdown
+		return nil
+	}
 	if err != nil {
 		log.Fatalln(err)
 	}
 	return err
 }
 
+func (p *Engine) listenAndServe(addr string, handler http.Handler) error {
+	srv := &http.Server{Addr: addr, Handler: handler}
+	p.srv.Store(srv)
+	if p.draining.Load() { // Shutdown is called before the server starts
+		return http.ErrServerClosed
+	}
+	if addr == "" {
+		addr = ":http"
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	p.StartJobs()
+	return srv.Serve(ln)
+}
+
 // SetLAS sets listenAndServe func to listens on the TCP network address addr
 // and to handle requests on incoming connections.
 func (p *Engine) SetLAS(listenAndServe func(addr string, handler http.Handler) error) {
@@ -166,10 +259,47 @@ func (p *Engine) SetDelims(left, right string) {
 	p.delimLeft, p.delimRight = left, right
 }
 
-func (p *Engine) templ(path string) *template.Template {
-	if p.tpl.Template == nil {
-		p.tpl.InitTemplates(p.yapFS(), p.delimLeft, p.delimRight, "_yap.html")
+// Funcs adds the elements of the argument map to the function map of YAP
+// templates. It must be called before the first template is rendered.
+func (p *Engine) Funcs(funcMap template.FuncMap) {
+	if p.funcs == nil {
+		p.funcs = make(template.FuncMap, len(funcMap))
+	}
+	for name, fn := range funcMap {
+		p.funcs[name] = fn
+	}
+}
+
+// ctxFunc adds a template function which is bound to the request context.
+// bind(ctx) returns the real template function and it must not use ctx until
+// the returned function is called (bind(nil) is called at parsing time).
+func (p *Engine) ctxFunc(name string, bind func(ctx *Context) any) {
+	if p.ctxFuncs == nil {
+		p.ctxFuncs = make(map[string]func(ctx *Context) any)
 	}
+	p.ctxFuncs[name] = bind
+}
+
+// bindFuncs returns template functions bound to ctx (see ctxFunc).
+func (p *Engine) bindFuncs(ctx *Context) template.FuncMap {
+	funcs := make(template.FuncMap, len(p.ctxFuncs))
+	for name, bind := range p.ctxFuncs {
+		funcs[name] = bind(ctx)
+	}
+	return funcs
+}
+
+func (p *Engine) templ(path string) *template.Template {
+	p.tplOnce.Do(func() {
+		funcs := make(template.FuncMap, len(p.funcs)+len(p.ctxFuncs))
+		for name, fn := range p.funcs {
+			funcs[name] = fn
+		}
+		for name, fn := range p.bindFuncs(nil) {
+			funcs[name] = fn
+		}
+		p.tpl.InitTemplates(p.yapFS(), p.delimLeft, p.delimRight, "_yap.html", funcs)
+	})
 	return p.tpl.Lookup(path)
 }
 
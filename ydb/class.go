@@ -17,6 +17,7 @@
 package ydb
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"log"
@@ -49,6 +50,8 @@ type Class struct {
 	test.Case
 
 	query *query // query
+
+	ctx context.Context // see SetContext
 }
 
 func (p *Class) initClass(self any) {
@@ -51,6 +51,11 @@ func (p *Sql) initSql() {
 	p.classes = make(map[string]*Class)
 }
 
+// DB returns the database object.
+func (p *Sql) DB() *sql.DB {
+	return p.db
+}
+
 // Engine initializes database by specified engine name.
 func (p *Sql) Engine__0(name string) {
 	driver, ok := engines[name]
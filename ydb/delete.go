@@ -32,9 +32,9 @@ func (p *Class) Delete(cond string, args ...any) (sql.Result, error) {
 	query := makeDeleteExpr(tbl, cond)
 	iArgSlice := checkArgSlice(args)
 	if iArgSlice >= 0 {
-		return p.deleteMulti(context.TODO(), query, iArgSlice, args)
+		return p.deleteMulti(p.Context(), query, iArgSlice, args)
 	}
-	return p.deleteOne(context.TODO(), query, args)
+	return p.deleteOne(p.Context(), query, args)
 }
 
 func makeDeleteExpr(tbl string, cond string) string {
@@ -57,7 +57,9 @@ func (p *Class) deleteOne(ctx context.Context, query string, args []any) (sql.Re
 	if debugExec {
 		log.Println("==>", query, args)
 	}
+	ctx, ob := observeQuery(ctx, "delete", p.tbl, query)
 	result, err := p.db.ExecContext(ctx, query, args...)
+	ob.end(err)
 	return p.deleteRet(result, err)
 }
 
@@ -17,7 +17,6 @@
 package ydb
 
 import (
-	"context"
 	"database/sql"
 	"log"
 	"reflect"
@@ -188,7 +187,9 @@ func (p *Class) insertRowsVals(tbl string, names []string, vals []any, rows int)
 	if debugExec {
 		log.Println("==>", q, vals)
 	}
-	result, err := p.db.ExecContext(context.TODO(), q, vals...)
+	ctx, ob := observeQuery(p.Context(), "insert", tbl, q)
+	result, err := p.db.ExecContext(ctx, q, vals...)
+	ob.end(err)
 	return p.insertRet(result, err)
 }
 
@@ -203,7 +204,9 @@ func (p *Class) insertRow(tbl string, names []string, vals []any) (sql.Result, e
 	if debugExec {
 		log.Println("==>", q, vals)
 	}
-	result, err := p.db.ExecContext(context.TODO(), q, vals...)
+	ctx, ob := observeQuery(p.Context(), "insert", tbl, q)
+	result, err := p.db.ExecContext(ctx, q, vals...)
+	ob.end(err)
 	return p.insertRet(result, err)
 }
 
@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ydb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/goplus/yap/metrics"
+	"github.com/goplus/yap/trace"
+)
+
+// Query timing is collected in the registry carried by the context of sql
+// operations (see metrics.NewContext), eg. the registry of an engine enabling
+// metrics (see yap.Engine.UseMetrics) for operations with the context of its
+// requests:
+//   - ydb_query_duration_seconds{op,table}
+//   - ydb_query_errors_total{op,table}
+//
+// op is one of "query", "count", "insert" and "delete".
+type queryMetrics struct {
+	duration *metrics.Histogram
+	errors   *metrics.Counter
+}
+
+var queryStats sync.Map // *metrics.Registry => *queryMetrics
+
+// queryMetricsOf returns query metrics of reg, or nil if reg is nil.
+func queryMetricsOf(reg *metrics.Registry) *queryMetrics {
+	if reg == nil {
+		return nil
+	}
+	if m, ok := queryStats.Load(reg); ok {
+		return m.(*queryMetrics)
+	}
+	m, _ := queryStats.LoadOrStore(reg, &queryMetrics{
+		duration: reg.Histogram("ydb_query_duration_seconds",
+			"ydb query latencies in seconds.", nil, "op", "table"),
+		errors: reg.Counter("ydb_query_errors_total",
+			"Total number of failed ydb queries.", "op", "table"),
+	})
+	return m.(*queryMetrics)
+}
+
+type queryObserver struct {
+	op, table string
+	start     time.Time
+	stats     *queryMetrics // nil if metrics are not collected
+	span      trace.Span
+}
+
+// observeQuery starts observing a sql operation: it collects metrics in the
+// registry of ctx, and starts a client span (named "ydb <op> <table>") as a
+// child of the span of ctx. The returned context carries the span.
+func observeQuery(ctx context.Context, op, table, query string) (context.Context, *queryObserver) {
+	ctx, span := trace.Start(ctx, "ydb "+op+" "+table, trace.KindClient)
+	span.SetAttr("db.operation", op)
+	span.SetAttr("db.sql.table", table)
+	span.SetAttr("db.statement", query)
+	stats := queryMetricsOf(metrics.FromContext(ctx))
+	return ctx, &queryObserver{op: op, table: table, start: time.Now(), stats: stats, span: span}
+}
+
+func (p *queryObserver) end(err error) {
+	m := p.stats
+	if m != nil {
+		m.duration.Since(p.start, p.op, p.table)
+	}
+	if err != nil && err != ErrNoRows {
+		if m != nil {
+			m.errors.Inc(p.op, p.table)
+		}
+		p.span.RecordError(err)
+	}
+	p.span.End()
+}
+
+// -----------------------------------------------------------------------------
+
+// SetContext sets the context of following sql operations, eg. the context of
+// an HTTP request (a *yap.Context is one), so they are cancelled with the
+// request. Spans of sql operations are started as children of the span of ctx
+// (see package github.com/goplus/yap/trace). A class shared by concurrent
+// requests should use WithContext instead.
+func (p *Class) SetContext(ctx context.Context) {
+	p.ctx = ctx
+}
+
+// WithContext returns a shallow copy of the class whose sql operations use
+// ctx (see SetContext). The copy has its own state of operations (eg. the
+// result checked by Ret), so copies of a shared class can be used by
+// concurrent requests. A class provided to a yap engine (see
+// yap.Engine.Provide) is injected into handlers by its copy with the request
+// context automatically.
+func (p *Class) WithContext(ctx context.Context) *Class {
+	ret := *p
+	ret.ctx = ctx
+	ret.result, ret.ret, ret.lastErr, ret.query = nil, nil, nil, nil
+	return &ret
+}
+
+// Context returns the context of sql operations. It is context.Background()
+// if SetContext isn't called.
+func (p *Class) Context() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
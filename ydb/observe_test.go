@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ydb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goplus/yap"
+	"github.com/goplus/yap/metrics"
+)
+
+func TestQueryMetrics(t *testing.T) {
+	_, q := observeQuery(context.Background(), "query", "users", "SELECT 1")
+	q.end(nil) // metrics not enabled
+	var b strings.Builder
+	metrics.Default.WriteText(&b)
+	if strings.Contains(b.String(), "ydb_") {
+		t.Fatalf("registered without a registry:\n%s", b.String())
+	}
+
+	reg, other := metrics.NewRegistry(), metrics.NewRegistry()
+	ctx := metrics.NewContext(context.Background(), reg)
+	_, q = observeQuery(ctx, "query", "users", "SELECT 1")
+	q.end(errors.New("failed"))
+	_, q = observeQuery(ctx, "count", "users", "SELECT COUNT(*)")
+	q.end(ErrNoRows)
+	_, q = observeQuery(metrics.NewContext(context.Background(), other), "insert", "users", "INSERT")
+	q.end(nil)
+	b.Reset()
+	reg.WriteText(&b)
+	for _, want := range []string{
+		`ydb_query_duration_seconds_count{op="query",table="users"} 1`,
+		`ydb_query_duration_seconds_count{op="count",table="users"} 1`,
+		`ydb_query_errors_total{op="query",table="users"} 1`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("metrics: %s not found\n%s", want, b.String())
+		}
+	}
+	if strings.Contains(b.String(), `ydb_query_errors_total{op="count"`) {
+		t.Fatal("ErrNoRows counted as an error")
+	}
+	if strings.Contains(b.String(), `op="insert"`) {
+		t.Fatal("metrics of another registry collected")
+	}
+}
+
+func TestEngineQueryMetrics(t *testing.T) {
+	regs := []*metrics.Registry{metrics.NewRegistry(), metrics.NewRegistry()}
+	for i, reg := range regs {
+		e := yap.New()
+		e.UseMetrics(yap.MetricsOptions{Registry: reg})
+		e.GET("/", func(ctx *yap.Context) {
+			_, q := observeQuery(ctx.Request.Context(), "query", "users", "SELECT 1")
+			q.end(nil)
+			ctx.TEXT(200, "text/plain", "ok")
+		})
+		for range i + 1 {
+			e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		}
+	}
+	for i, reg := range regs {
+		var b strings.Builder
+		reg.WriteText(&b)
+		want := `ydb_query_duration_seconds_count{op="query",table="users"} ` + strconv.Itoa(i+1)
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("metrics of engine %d: %s not found\n%s", i, want, b.String())
+		}
+	}
+}
+
+type ctxKey struct{}
+
+func TestWithContext(t *testing.T) {
+	shared := new(Class)
+	shared.OnErr(func(error) {})
+	shared.result = []reflect.Value{reflect.ValueOf(1)}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "req")
+	c := shared.WithContext(ctx)
+	if c == shared || c.Context() != ctx || c.result != nil || c.onErr == nil {
+		t.Fatal("WithContext:", c)
+	}
+	if shared.Context() != context.Background() || shared.result == nil {
+		t.Fatal("WithContext: shared class changed")
+	}
+}
+
+// classHandler reports the request id seen by the context of its class.
+type classHandler struct {
+	yap.Handler
+	DB *Class `yap:"inject"`
+}
+
+func (p *classHandler) Main(ctx *yap.Context) {
+	p.Handler.Main(ctx)
+	time.Sleep(time.Millisecond)
+	ctx.Text__2(p.DB.Context().Value(ctxKey{}).(string))
+}
+
+func (p *classHandler) Classclone() yap.HandlerProto {
+	ret := *p
+	return &ret
+}
+
+func TestInjectWithContext(t *testing.T) {
+	shared := new(Class)
+	e := yap.New()
+	e.Provide(shared)
+	e.ProtoRoute("GET", "/id/:id", &classHandler{})
+	h := e.Handler(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := strings.TrimPrefix(r.URL.Path, "/id/")
+			h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxKey{}, id)))
+		})
+	})
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"1", "2", "3", "4"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", "/id/"+id, nil))
+			if w.Body.String() != id {
+				t.Error("request", id, "got context of", w.Body.String())
+			}
+		}()
+	}
+	wg.Wait()
+	if shared.ctx != nil {
+		t.Fatal("shared class bound to a request")
+	}
+}
@@ -117,7 +117,7 @@ func (p *Class) queryStrucRow(vRet reflect.Value) error {
 
 	q := p.query
 	query := q.makeSelectExpr(p.tbl, names)
-	return p.queryVals(context.TODO(), query, q.args, rets)
+	return p.queryVals(p.Context(), query, q.args, rets)
 }
 
 func (p *Class) queryStrucOne(
@@ -177,9 +177,9 @@ func (p *Class) queryStrucRows(vSlice reflect.Value) error {
 	args := q.args
 	iArgSlice := checkArgSlice(args)
 	if iArgSlice >= 0 {
-		return p.queryStrucMulti(context.TODO(), query, args, iArgSlice, vSlice, elem, cols, hasPtr)
+		return p.queryStrucMulti(p.Context(), query, args, iArgSlice, vSlice, elem, cols, hasPtr)
 	}
-	return p.queryStrucOne(context.TODO(), query, args, vSlice, elem, cols, hasPtr)
+	return p.queryStrucOne(p.Context(), query, args, vSlice, elem, cols, hasPtr)
 }
 
 // queryVals NOTE:
@@ -193,7 +193,9 @@ func (p *Class) queryVals(ctx context.Context, query string, args, rets []any) e
 	if debugExec {
 		log.Println("==>", query, args)
 	}
+	ctx, ob := observeQuery(ctx, "query", p.tbl, query)
 	rows, err := p.db.QueryContext(ctx, query, args...)
+	ob.end(err)
 	p.lastErr = err
 	if err != nil {
 		p.handleErr("query:", err)
@@ -263,7 +265,9 @@ func (p *Class) queryRows(ctx context.Context, query string, args, rets []any) e
 	if debugExec {
 		log.Println("==>", query, args)
 	}
+	ctx, ob := observeQuery(ctx, "query", p.tbl, query)
 	rows, err := p.db.QueryContext(ctx, query, args...)
+	ob.end(err)
 	p.lastErr = err
 	if err != nil {
 		p.handleErr("query:", err)
@@ -292,7 +296,9 @@ func (p *Class) queryMultiOne(ctx context.Context, query string, args, oneRet []
 	if debugExec {
 		log.Println("==>", query, args)
 	}
+	ctx, ob := observeQuery(ctx, "query", p.tbl, query)
 	rows, err := p.db.QueryContext(ctx, query, args...)
+	ob.end(err)
 	p.lastErr = err
 	if err != nil {
 		p.handleErr("query:", err)
@@ -357,9 +363,9 @@ func (p *Class) queryRetKvPair(kvPair ...any) error {
 
 	query := q.makeSelectExpr(tbl, exprs)
 	if kind == valFlagNormal {
-		return p.queryVals(context.TODO(), query, q.args, rets)
+		return p.queryVals(p.Context(), query, q.args, rets)
 	}
-	return p.queryRows(context.TODO(), query, q.args, rets)
+	return p.queryRows(p.Context(), query, q.args, rets)
 }
 
 func retKind(ret any) int {
@@ -406,8 +412,12 @@ func (p *Class) Count(cond string, args ...any) (n int, err error) {
 	if p.tbl == "" {
 		log.Panicln("please call `use <tableName>` to specified a table name")
 	}
-	row := p.db.QueryRowContext(context.TODO(), "SELECT COUNT(*) FROM "+p.tbl+" WHERE "+cond, args...)
-	if err = row.Scan(&n); err != nil {
+	query := "SELECT COUNT(*) FROM " + p.tbl + " WHERE " + cond
+	ctx, ob := observeQuery(p.Context(), "count", p.tbl, query)
+	row := p.db.QueryRowContext(ctx, query, args...)
+	err = row.Scan(&n)
+	ob.end(err)
+	if err != nil {
 		p.handleErr("query:", err)
 	}
 	return
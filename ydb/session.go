@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ydb
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// SessionStore is a yap session store (see yap.SessionStore) which saves
+// sessions in a sql table:
+//
+//	CREATE TABLE <table> (id CHAR(64) PRIMARY KEY, data BLOB, expire BIGINT)
+type SessionStore struct {
+	db  *sql.DB
+	tbl string
+	now func() time.Time
+}
+
+// NewSessionStore creates a SessionStore by specified database and table
+// name. The table is created if it doesn't exist.
+func NewSessionStore(db *sql.DB, table string) (*SessionStore, error) {
+	_, err := db.ExecContext(context.Background(),
+		"CREATE TABLE IF NOT EXISTS "+table+" (id CHAR(64) PRIMARY KEY, data BLOB, expire BIGINT)")
+	if err != nil {
+		return nil, err
+	}
+	return &SessionStore{db: db, tbl: table, now: time.Now}, nil
+}
+
+// Load returns session data of a session id. It returns (nil, nil) if the
+// session doesn't exist or is expired.
+func (p *SessionStore) Load(id string) ([]byte, error) {
+	return p.LoadContext(context.Background(), id)
+}
+
+// LoadContext is Load with a context, eg. of the HTTP request. yap calls it
+// instead of Load (see yap.ContextSessionStore).
+func (p *SessionStore) LoadContext(ctx context.Context, id string) (data []byte, err error) {
+	var expire int64
+	row := p.db.QueryRowContext(ctx, "SELECT data,expire FROM "+p.tbl+" WHERE id=?", id)
+	if err = row.Scan(&data, &expire); err != nil {
+		if err == sql.ErrNoRows {
+			err = nil
+		}
+		return nil, err
+	}
+	if p.now().Unix() > expire {
+		return nil, nil
+	}
+	return
+}
+
+// Save saves session data. If id is empty, a new session id is generated.
+func (p *SessionStore) Save(id string, data []byte, maxAge time.Duration) (string, error) {
+	return p.SaveContext(context.Background(), id, data, maxAge)
+}
+
+// SaveContext is Save with a context. See LoadContext.
+func (p *SessionStore) SaveContext(ctx context.Context, id string, data []byte, maxAge time.Duration) (string, error) {
+	if id == "" {
+		var b [32]byte
+		if _, err := rand.Read(b[:]); err != nil {
+			return "", err
+		}
+		id = base64.RawURLEncoding.EncodeToString(b[:])
+	}
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	expire := p.now().Add(maxAge).Unix()
+	if _, err = tx.ExecContext(ctx, "DELETE FROM "+p.tbl+" WHERE id=?", id); err != nil {
+		return "", err
+	}
+	if _, err = tx.ExecContext(ctx, "INSERT INTO "+p.tbl+" (id,data,expire) VALUES (?,?,?)", id, data, expire); err != nil {
+		return "", err
+	}
+	return id, tx.Commit()
+}
+
+// Delete removes a session.
+func (p *SessionStore) Delete(id string) error {
+	return p.DeleteContext(context.Background(), id)
+}
+
+// DeleteContext is Delete with a context. See LoadContext.
+func (p *SessionStore) DeleteContext(ctx context.Context, id string) error {
+	_, err := p.db.ExecContext(ctx, "DELETE FROM "+p.tbl+" WHERE id=?", id)
+	return err
+}
+
+// Cleanup removes all expired sessions.
+func (p *SessionStore) Cleanup() error {
+	_, err := p.db.ExecContext(context.Background(), "DELETE FROM "+p.tbl+" WHERE expire<?", p.now().Unix())
+	return err
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ydb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/goplus/yap"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var _ yap.ContextSessionStore = (*SessionStore)(nil)
+
+func newTestStore(t *testing.T) *SessionStore {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1) // each connection has its own memory database
+	t.Cleanup(func() { db.Close() })
+	store, err := NewSessionStore(db, "sessions")
+	if err != nil {
+		t.Fatal("NewSessionStore:", err)
+	}
+	return store
+}
+
+func TestSessionStore(t *testing.T) {
+	store := newTestStore(t)
+	id, err := store.Save("", []byte(`{"user":"bob"}`), time.Hour)
+	if err != nil || len(id) != 43 {
+		t.Fatal("Save:", id, err)
+	}
+	if data, err := store.Load(id); err != nil || string(data) != `{"user":"bob"}` {
+		t.Fatal("Load:", string(data), err)
+	}
+	if id2, err := store.Save(id, []byte(`{"user":"amy"}`), time.Hour); err != nil || id2 != id {
+		t.Fatal("Save: update", id2, err)
+	}
+	if data, _ := store.Load(id); string(data) != `{"user":"amy"}` {
+		t.Fatal("Load: updated", string(data))
+	}
+	if data, err := store.Load("none"); data != nil || err != nil {
+		t.Fatal("Load: not found", data, err)
+	}
+	if err = store.Delete(id); err != nil {
+		t.Fatal("Delete:", err)
+	}
+	if data, _ := store.Load(id); data != nil {
+		t.Fatal("Load: deleted", string(data))
+	}
+}
+
+func TestSessionStoreExpire(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	id, _ := store.Save("", []byte("{}"), time.Minute)
+	keep, _ := store.Save("", []byte("{}"), time.Hour)
+
+	now = now.Add(2 * time.Minute)
+	if data, err := store.Load(id); data != nil || err != nil {
+		t.Fatal("Load: expired", data, err)
+	}
+	if err := store.Cleanup(); err != nil {
+		t.Fatal("Cleanup:", err)
+	}
+	var n int
+	store.db.QueryRow("SELECT COUNT(*) FROM sessions").Scan(&n)
+	if n != 1 {
+		t.Fatal("Cleanup: sessions", n)
+	}
+	if data, _ := store.Load(keep); data == nil {
+		t.Fatal("Cleanup: removed a live session")
+	}
+}
+
+func TestSessionStoreContext(t *testing.T) {
+	store := newTestStore(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := store.SaveContext(ctx, "", []byte("{}"), time.Hour); err == nil {
+		t.Fatal("SaveContext: not cancelled")
+	}
+	if _, err := store.LoadContext(ctx, "x"); err == nil {
+		t.Fatal("LoadContext: not cancelled")
+	}
+}
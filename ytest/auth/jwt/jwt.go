@@ -17,6 +17,9 @@
 package jwt
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"log"
 	"net/http"
 	"time"
@@ -31,6 +34,13 @@ type Signaturer struct {
 	method jwt.SigningMethod
 	claims jwt.MapClaims
 	secret any
+	kid    string
+}
+
+// KeyID sets the "kid" header of the token.
+func (p *Signaturer) KeyID(kid string) *Signaturer {
+	p.kid = kid
+	return p
 }
 
 func (p *Signaturer) Set(k string, v any) *Signaturer {
@@ -60,6 +70,9 @@ func (p *Signaturer) IssuedAt(iat time.Time) *Signaturer {
 
 func (p *Signaturer) Compose(rt http.RoundTripper) http.RoundTripper {
 	token := jwt.NewWithClaims(p.method, p.claims)
+	if p.kid != "" {
+		token.Header["kid"] = p.kid
+	}
 	raw, err := token.SignedString(p.secret)
 	if err != nil {
 		log.Panicln("jwt token.SignedString:", err)
@@ -90,4 +103,19 @@ func HS512(key string) *Signaturer {
 	return newSign(jwt.SigningMethodHS512, []byte(key))
 }
 
+// RS256 creates a signing methods by using the RSASSA-PKCS1-v1_5 SHA-256.
+func RS256(key *rsa.PrivateKey) *Signaturer {
+	return newSign(jwt.SigningMethodRS256, key)
+}
+
+// ES256 creates a signing methods by using the ECDSA P-256 SHA-256.
+func ES256(key *ecdsa.PrivateKey) *Signaturer {
+	return newSign(jwt.SigningMethodES256, key)
+}
+
+// EdDSA creates a signing methods by using the Ed25519.
+func EdDSA(key ed25519.PrivateKey) *Signaturer {
+	return newSign(jwt.SigningMethodEdDSA, key)
+}
+
 // -----------------------------------------------------------------------------
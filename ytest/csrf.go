@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ytest
+
+import (
+	"net/http"
+
+	"github.com/goplus/yap"
+	"github.com/qiniu/x/test"
+)
+
+// -----------------------------------------------------------------------------
+
+// CSRFToken fetches a CSRF token by sending a GET request to url. The token is
+// read from the token cookie set by the yap CSRF middleware. For example:
+//
+//	token := csrfToken("http://foo.com/form")
+//	post "http://foo.com/articles"
+//	csrf token
+//	form {"title": "hello"}
+//	ret 200
+//
+// If the app uses yap.CSRFOptions with another cookie name, pass the same
+// options to both CSRFToken and CSRF.
+func (p *Case) CSRFToken(url string, opts ...yap.CSRFOptions) string {
+	cookieName, _ := csrfNames(opts)
+	resp, err := newRequest(p, http.MethodGet, url).doSend()
+	if err != nil {
+		test.Fatalf("CSRFToken(%v) failed: %v\n", url, err)
+	}
+	defer resp.Body.Close()
+	for _, c := range resp.Cookies() {
+		if c.Name == cookieName {
+			return c.Value
+		}
+	}
+	test.Fatalf("CSRFToken(%v) failed: no csrf cookie\n", url)
+	return ""
+}
+
+// CSRF attaches a CSRF token to this request: it sends the token both in the
+// token cookie and in the token header, named by opts (default names are
+// yap.DefaultCSRFCookie and yap.DefaultCSRFHeader).
+func (p *Request) CSRF(token string, opts ...yap.CSRFOptions) *Request {
+	cookieName, headerName := csrfNames(opts)
+	p.header.Set(headerName, token)
+	p.header.Add("Cookie", (&http.Cookie{Name: cookieName, Value: token}).String())
+	return p
+}
+
+func csrfNames(opts []yap.CSRFOptions) (cookieName, headerName string) {
+	cookieName, headerName = yap.DefaultCSRFCookie, yap.DefaultCSRFHeader
+	if opts != nil {
+		if opts[0].CookieName != "" {
+			cookieName = opts[0].CookieName
+		}
+		if opts[0].HeaderName != "" {
+			headerName = opts[0].HeaderName
+		}
+	}
+	return
+}
+
+// -----------------------------------------------------------------------------